@@ -1,25 +1,41 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/bventy/backend/internal/auth"
 	"github.com/bventy/backend/internal/config"
 	"github.com/bventy/backend/internal/db"
 	"github.com/bventy/backend/internal/routes"
+	"github.com/bventy/backend/internal/services"
 )
 
 func main() {
 
 	// Step 0: Load config
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("❌ Refusing to start: ", err)
+	}
+	if err := auth.InitKeys(cfg); err != nil {
+		log.Fatal("❌ Failed to load JWT keys: ", err)
+	}
 
 	// Step 1: Connect DB
 	db.Connect(cfg)
 
+	// Step 1.5: Background jobs
+	media, err := services.NewMediaService(cfg)
+	if err != nil {
+		log.Printf("⚠️  Warning: storage unavailable, deleted accounts will keep their private files: %v", err)
+	}
+	services.StartAccountDeletionWorker(context.Background(), time.Hour, media)
+
 	// Step 2: Start Gin server
 	r := gin.Default()
 
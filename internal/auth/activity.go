@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bventy/backend/internal/db"
+)
+
+// lastSeenResolution is how stale last-seen / last-used timestamps may get.
+// Requests inside the window skip the write entirely.
+const lastSeenResolution = time.Minute
+
+var (
+	touchMu    sync.Mutex
+	touches    = map[string]time.Time{}
+	lastPruned time.Time
+)
+
+// shouldTouch reports whether this process has not written key within the window.
+// The SQL guards in the callers cover other instances that wrote more recently.
+func shouldTouch(key string) bool {
+	now := time.Now()
+
+	touchMu.Lock()
+	defer touchMu.Unlock()
+
+	if last, ok := touches[key]; ok && now.Sub(last) < lastSeenResolution {
+		return false
+	}
+	touches[key] = now
+
+	// Drop expired keys once per window, so the map only holds keys seen in
+	// roughly the last two windows
+	if now.Sub(lastPruned) >= lastSeenResolution {
+		for k, t := range touches {
+			if now.Sub(t) >= lastSeenResolution {
+				delete(touches, k)
+			}
+		}
+		lastPruned = now
+	}
+	return true
+}
+
+// TouchSession records activity on a session without writing on every request.
+func TouchSession(sessionID string) {
+	if !shouldTouch("session:" + sessionID) {
+		return
+	}
+
+	go func() {
+		_, _ = db.Pool.Exec(context.Background(), `
+			UPDATE user_sessions SET last_seen_at = NOW()
+			WHERE id = $1 AND (last_seen_at IS NULL OR last_seen_at < NOW() - make_interval(secs => $2))
+		`, sessionID, lastSeenResolution.Seconds())
+	}()
+}
+
+// TouchAPIKey records when and from where a key was last used, with the same throttling.
+func TouchAPIKey(keyID, ip string) {
+	if !shouldTouch("apikey:" + keyID) {
+		return
+	}
+
+	go func() {
+		_, _ = db.Pool.Exec(context.Background(), `
+			UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $2
+			WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - make_interval(secs => $3))
+		`, keyID, ip, lastSeenResolution.Seconds())
+	}()
+}
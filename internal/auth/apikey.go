@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/db"
+)
+
+const APIKeyPrefix = "bv_"
+
+var ErrInvalidAPIKey = errors.New("invalid or revoked API key")
+
+// APIKeyScopes lists every scope a key can be granted
+var APIKeyScopes = []string{
+	"quotes:read",    // list the vendor's quote inbox
+	"quotes:respond", // send prices and messages on quotes
+	"vendor:read",    // read the vendor profile
+	"vendor:write",   // update the vendor profile, gallery and portfolio
+}
+
+// IsValidAPIKeyScope reports whether scope is one of APIKeyScopes
+func IsValidAPIKeyScope(scope string) bool {
+	for _, s := range APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyPrincipal is who a valid key authenticates as
+type APIKeyPrincipal struct {
+	KeyID  string
+	UserID string
+	Role   string
+	Scopes []string
+}
+
+// HasScope reports whether the key was granted scope
+func (p *APIKeyPrincipal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAPIKey returns a new key, its public prefix and the hash to store.
+func GenerateAPIKey() (string, string, string, error) {
+	idBytes := make([]byte, 6)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	prefix := APIKeyPrefix + hex.EncodeToString(idBytes)
+	key := prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, HashToken(key), nil
+}
+
+// LooksLikeAPIKey tells API keys apart from JWTs in the Authorization header
+func LooksLikeAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// ValidateAPIKey resolves a key to its owner. Keys of suspended users stop working.
+func ValidateAPIKey(ctx context.Context, key string) (*APIKeyPrincipal, error) {
+	sep := strings.LastIndex(key, "_")
+	if !LooksLikeAPIKey(key) || sep <= len(APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	prefix := key[:sep]
+
+	var p APIKeyPrincipal
+	var keyHash string
+	var suspendedAt *time.Time
+	query := `
+		SELECT k.id, k.user_id, k.key_hash, k.scopes, u.role, u.suspended_at
+		FROM api_keys k
+		JOIN users u ON k.user_id = u.id
+		WHERE k.prefix = $1 AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > NOW())
+	`
+	err := db.Pool.QueryRow(ctx, query, prefix).Scan(&p.KeyID, &p.UserID, &keyHash, &p.Scopes, &p.Role, &suspendedAt)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(HashToken(key)), []byte(keyHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	if suspendedAt != nil {
+		return nil, ErrAccountSuspended
+	}
+
+	return &p, nil
+}
@@ -9,33 +9,119 @@ import (
 )
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
+	Version   int    `json:"ver"`
+	Purpose   string `json:"purpose,omitempty"`
+	// Set only on impersonation tokens: the super_admin acting as UserID
+	ImpersonatorID string `json:"imp,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID string, role string, cfg *config.Config) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// Access and challenge tokens share the signing key, so each carries its own
+// audience and is only accepted where that audience is expected.
+const (
+	accessTokenAudience    = "bventy-api"
+	challengeTokenAudience = "bventy-2fa-challenge"
+)
+
+// GenerateToken issues a short-lived access token bound to a session and to the
+// user's current token version. Revoking the session (logout, refresh reuse) or
+// bumping users.token_version invalidates the token early.
+func GenerateToken(userID string, role string, sessionID string, version int, cfg *config.Config) (string, error) {
+	now := time.Now()
 	claims := &Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		Version:   version,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "bventy-backend",
+			Audience:  jwt.ClaimStrings{accessTokenAudience},
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWTSecret))
+	return signClaims(claims, cfg)
 }
 
 func ValidateToken(tokenString string, cfg *config.Config) (*Claims, error) {
-	claims := &Claims{}
+	claims, err := parseClaims(tokenString, accessTokenAudience, cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
+	// Purpose-bound tokens (e.g. 2FA challenges) never grant API access
+	if claims.Purpose != "" {
+		return nil, errors.New("token cannot be used for API access")
+	}
+
+	// Tokens minted before sessions existed carry no sid and are no longer accepted
+	if claims.SessionID == "" {
+		return nil, errors.New("token is not bound to a session")
+	}
 
+	// Authorization must reflect the database, not what was true at issue time
+	role, err := checkTokenState(claims)
+	if err != nil {
+		return nil, err
+	}
+	claims.Role = role
+
+	return claims, nil
+}
+
+const twoFactorChallengeTTL = 5 * time.Minute
+
+// GenerateChallengeToken proves the password step of login succeeded.
+// It can only be exchanged for a session together with a valid 2FA code.
+func GenerateChallengeToken(userID string, cfg *config.Config) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:  userID,
+		Purpose: "2fa_challenge",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(twoFactorChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "bventy-backend",
+			Audience:  jwt.ClaimStrings{challengeTokenAudience},
+		},
+	}
+
+	return signClaims(claims, cfg)
+}
+
+func ValidateChallengeToken(tokenString string, cfg *config.Config) (string, error) {
+	claims, err := parseClaims(tokenString, challengeTokenAudience, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	if claims.Purpose != "2fa_challenge" {
+		return "", errors.New("invalid challenge token")
+	}
+
+	return claims.UserID, nil
+}
+
+func signClaims(claims *Claims, cfg *config.Config) (string, error) {
+	ks, err := getKeys(cfg)
+	if err != nil {
+		return "", err
+	}
+	return ks.sign(claims)
+}
+
+func parseClaims(tokenString string, audience string, cfg *config.Config) (*Claims, error) {
+	ks, err := getKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, ks.keyFunc, jwt.WithValidMethods(ks.validMethods()), jwt.WithAudience(audience))
 	if err != nil {
 		return nil, err
 	}
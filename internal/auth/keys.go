@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/bventy/backend/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet holds the key used to sign new tokens and every key still accepted
+// for verification. Retired keys stay in Verification until their tokens expire.
+type KeySet struct {
+	SigningMethod jwt.SigningMethod
+	SigningKeyID  string
+	SigningKey    interface{}
+	Verification  map[string]verificationKey
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+var (
+	keys     *KeySet
+	keysErr  error
+	keysOnce sync.Once
+)
+
+// InitKeys loads signing and verification keys from config. Call it at startup
+// so a bad key fails fast; otherwise keys are loaded on first use.
+func InitKeys(cfg *config.Config) error {
+	keysOnce.Do(func() {
+		keys, keysErr = loadKeySet(cfg)
+	})
+	return keysErr
+}
+
+func getKeys(cfg *config.Config) (*KeySet, error) {
+	if err := InitKeys(cfg); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func loadKeySet(cfg *config.Config) (*KeySet, error) {
+	ks := &KeySet{Verification: map[string]verificationKey{}}
+
+	switch cfg.JWTSigningAlg {
+	case "", "HS256":
+		ks.SigningMethod = jwt.SigningMethodHS256
+		ks.SigningKeyID = ""
+		ks.SigningKey = []byte(cfg.JWTSecret)
+		// Shared-secret tokens carry no kid
+		ks.Verification[""] = verificationKey{method: jwt.SigningMethodHS256, key: []byte(cfg.JWTSecret)}
+	case "RS256", "EdDSA":
+		pemData, err := readPEM(cfg.JWTPrivateKey, cfg.JWTPrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+		}
+		private, err := parsePrivateKey(pemData)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.JWTKeyID == "" {
+			return nil, errors.New("JWT_KEY_ID is required for asymmetric signing")
+		}
+
+		method, public, err := methodFor(private)
+		if err != nil {
+			return nil, err
+		}
+		if method.Alg() != cfg.JWTSigningAlg {
+			return nil, fmt.Errorf("JWT private key type does not match JWT_SIGNING_ALG %s", cfg.JWTSigningAlg)
+		}
+
+		ks.SigningMethod = method
+		ks.SigningKeyID = cfg.JWTKeyID
+		ks.SigningKey = private
+		ks.Verification[cfg.JWTKeyID] = verificationKey{method: method, key: public}
+	default:
+		return nil, fmt.Errorf("unsupported JWT_SIGNING_ALG %q", cfg.JWTSigningAlg)
+	}
+
+	// Additional public keys, e.g. the previous signing key during rotation.
+	// Format: kid=/path/to/public.pem,kid2=/path/to/other.pem
+	for _, entry := range strings.Split(cfg.JWTVerificationKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid JWT_VERIFICATION_KEYS entry %q", entry)
+		}
+		pemData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read verification key %s: %w", kid, err)
+		}
+		public, err := parsePublicKey(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("verification key %s: %w", kid, err)
+		}
+		method, err := methodForPublic(public)
+		if err != nil {
+			return nil, err
+		}
+		ks.Verification[kid] = verificationKey{method: method, key: public}
+	}
+
+	return ks, nil
+}
+
+// keyFunc resolves the verification key from the token's kid header and
+// refuses any algorithm other than the one registered for that key.
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	vk, ok := ks.Verification[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != vk.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return vk.key, nil
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.SigningMethod, claims)
+	if ks.SigningKeyID != "" {
+		token.Header["kid"] = ks.SigningKeyID
+	}
+	return token.SignedString(ks.SigningKey)
+}
+
+func (ks *KeySet) validMethods() []string {
+	seen := map[string]bool{}
+	var methods []string
+	for _, vk := range ks.Verification {
+		if !seen[vk.method.Alg()] {
+			seen[vk.method.Alg()] = true
+			methods = append(methods, vk.method.Alg())
+		}
+	}
+	return methods
+}
+
+// JWKS returns the public verification keys as a JSON Web Key Set.
+// Shared HS256 secrets are never published.
+func JWKS(cfg *config.Config) (map[string]interface{}, error) {
+	ks, err := getKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	jwks := []map[string]interface{}{}
+	for kid, vk := range ks.Verification {
+		switch pub := vk.key.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, map[string]interface{}{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, map[string]interface{}{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"kid": kid,
+				"use": "sig",
+				"alg": "EdDSA",
+				"x":   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return map[string]interface{}{"keys": jwks}, nil
+}
+
+func readPEM(inline, path string) ([]byte, error) {
+	if inline != "" {
+		// Platforms often store multi-line secrets with escaped newlines
+		return []byte(strings.ReplaceAll(inline, `\n`, "\n")), nil
+	}
+	if path == "" {
+		return nil, errors.New("set JWT_PRIVATE_KEY or JWT_PRIVATE_KEY_PATH")
+	}
+	return os.ReadFile(path)
+}
+
+func parsePrivateKey(pemData []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("no PEM block found in private key")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key type")
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("failed to parse private key (expected PKCS#8 or PKCS#1 PEM)")
+}
+
+func parsePublicKey(pemData []byte) (interface{}, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("no PEM block found in public key")
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("failed to parse public key (expected PKIX or PKCS#1 PEM)")
+}
+
+func methodFor(private crypto.Signer) (jwt.SigningMethod, interface{}, error) {
+	public := private.Public()
+	method, err := methodForPublic(public)
+	return method, public, err
+}
+
+func methodForPublic(public interface{}) (jwt.SigningMethod, error) {
+	switch public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, errors.New("unsupported key type (use RSA or Ed25519)")
+	}
+}
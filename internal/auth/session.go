@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bventy/backend/internal/config"
+	"github.com/bventy/backend/internal/db"
+	"github.com/golang-jwt/jwt/v5"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrTokenOutdated       = errors.New("token is outdated, please refresh")
+	ErrAccountSuspended    = errors.New("account is suspended")
+)
+
+// TokenPair is returned to the client on signup, login and refresh.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	SessionID    string
+	ExpiresIn    int64 // access token lifetime in seconds
+}
+
+// GenerateOpaqueToken returns a random URL-safe token and its SHA-256 hash.
+// Only the hash is ever stored.
+func GenerateOpaqueToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken hashes an opaque token for storage and lookup.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession starts a new session for the user and issues its first token pair.
+// Role and token version are read from the database, never taken from the caller.
+func CreateSession(ctx context.Context, userID, userAgent, ipAddress string, cfg *config.Config) (*TokenPair, error) {
+	refreshToken, refreshHash, err := GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var role string
+	var version int
+	var suspendedAt *time.Time
+	err = tx.QueryRow(ctx, `SELECT role, token_version, suspended_at FROM users WHERE id = $1`, userID).Scan(&role, &version, &suspendedAt)
+	if err != nil {
+		return nil, err
+	}
+	if suspendedAt != nil {
+		return nil, ErrAccountSuspended
+	}
+
+	expiresAt := time.Now().Add(cfg.RefreshTokenTTL)
+
+	var sessionID string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO user_sessions (user_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, userID, userAgent, ipAddress, expiresAt).Scan(&sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (session_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`, sessionID, refreshHash, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := GenerateToken(userID, role, sessionID, version, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		SessionID:    sessionID,
+		ExpiresIn:    int64(cfg.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// CreateImpersonationSession opens a short-lived, non-refreshable session for
+// targetUserID on behalf of impersonatorID and returns its access token.
+func CreateImpersonationSession(ctx context.Context, targetUserID, impersonatorID, userAgent, ipAddress string, cfg *config.Config) (*TokenPair, error) {
+	var role string
+	var version int
+	err := db.Pool.QueryRow(ctx, `SELECT role, token_version FROM users WHERE id = $1`, targetUserID).Scan(&role, &version)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessionID string
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO user_sessions (user_id, user_agent, ip_address, expires_at, impersonator_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, targetUserID, userAgent, ipAddress, time.Now().Add(cfg.ImpersonationTTL), impersonatorID).Scan(&sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:         targetUserID,
+		Role:           role,
+		SessionID:      sessionID,
+		Version:        version,
+		ImpersonatorID: impersonatorID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.ImpersonationTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "bventy-backend",
+			Audience:  jwt.ClaimStrings{accessTokenAudience},
+		},
+	}
+	accessToken, err := signClaims(claims, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken: accessToken,
+		SessionID:   sessionID,
+		ExpiresIn:   int64(cfg.ImpersonationTTL.Seconds()),
+	}, nil
+}
+
+// RefreshSession rotates a refresh token. Presenting a token that was already
+// rotated means it leaked, so the whole session is revoked.
+func RefreshSession(ctx context.Context, refreshToken string, cfg *config.Config) (*TokenPair, error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var tokenID, sessionID, userID, role string
+	var version int
+	var usedAt, sessionRevokedAt, suspendedAt *time.Time
+	var tokenExpiresAt, sessionExpiresAt time.Time
+
+	query := `
+		SELECT rt.id, rt.session_id, rt.used_at, rt.expires_at, s.user_id, s.revoked_at, s.expires_at,
+		       u.role, u.token_version, u.suspended_at
+		FROM refresh_tokens rt
+		JOIN user_sessions s ON rt.session_id = s.id
+		JOIN users u ON s.user_id = u.id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF rt, s
+	`
+	err = tx.QueryRow(ctx, query, HashToken(refreshToken)).Scan(
+		&tokenID, &sessionID, &usedAt, &tokenExpiresAt, &userID, &sessionRevokedAt, &sessionExpiresAt,
+		&role, &version, &suspendedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if sessionRevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+
+	if usedAt != nil {
+		_, err = tx.Exec(ctx, `UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = 'refresh_token_reuse' WHERE id = $1`, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		log.Printf("⚠️  Refresh token reuse detected, revoked session %s for user %s", sessionID, userID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	if suspendedAt != nil {
+		return nil, ErrAccountSuspended
+	}
+
+	now := time.Now()
+	if now.After(tokenExpiresAt) || now.After(sessionExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	newToken, newHash, err := GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1`, tokenID); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE user_sessions SET last_seen_at = NOW() WHERE id = $1`, sessionID); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (session_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`, sessionID, newHash, sessionExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := GenerateToken(userID, role, sessionID, version, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: newToken,
+		SessionID:    sessionID,
+		ExpiresIn:    int64(cfg.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RevokeSessionByRefreshToken ends the session the refresh token belongs to.
+// Unknown tokens are ignored so logout stays idempotent.
+func RevokeSessionByRefreshToken(ctx context.Context, refreshToken string) error {
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = 'logout'
+		WHERE id = (SELECT session_id FROM refresh_tokens WHERE token_hash = $1)
+		AND revoked_at IS NULL
+	`, HashToken(refreshToken))
+	return err
+}
+
+// RevokeSession ends a single session.
+func RevokeSession(ctx context.Context, sessionID, reason string) error {
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, sessionID, reason)
+	return err
+}
+
+// RevokeUserSessions ends every live session of the user except keepSessionID
+// (pass "" to sign out everywhere).
+func RevokeUserSessions(ctx context.Context, userID, keepSessionID, reason string) error {
+	_, err := db.Pool.Exec(ctx, `
+		UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE user_id = $1 AND revoked_at IS NULL AND id::text != $2
+	`, userID, keepSessionID, reason)
+	return err
+}
+
+// checkTokenState verifies the token's session is live and its version matches
+// the user's, returning the user's current role.
+func checkTokenState(claims *Claims) (string, error) {
+	var sessionActive bool
+	var role string
+	var version int
+	var suspendedAt *time.Time
+
+	// COALESCE(impersonator_id::text, '') must match the claim, so a normal
+	// session can never be used with an impersonation token or vice versa
+	query := `
+		SELECT s.revoked_at IS NULL AND s.expires_at > NOW(), u.role, u.token_version, u.suspended_at
+		FROM user_sessions s
+		JOIN users u ON s.user_id = u.id
+		WHERE s.id = $1 AND s.user_id = $2 AND COALESCE(s.impersonator_id::text, '') = $3
+	`
+	err := db.Pool.QueryRow(context.Background(), query, claims.SessionID, claims.UserID, claims.ImpersonatorID).Scan(&sessionActive, &role, &version, &suspendedAt)
+	if err != nil || !sessionActive {
+		return "", ErrSessionRevoked
+	}
+	if suspendedAt != nil {
+		return "", ErrAccountSuspended
+	}
+	if version != claims.Version {
+		return "", ErrTokenOutdated
+	}
+	return role, nil
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/config"
+	"github.com/bventy/backend/internal/db"
+)
+
+// LoginThrottleResult describes whether a login attempt may proceed
+type LoginThrottleResult struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+// Allowed reports whether the attempt may go ahead now
+func (r LoginThrottleResult) Allowed() bool {
+	return r.RetryAfter <= 0
+}
+
+// NormalizeEmail is the throttle key for an account
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// CheckLoginAllowed looks at both the account and the client IP. The longest
+// remaining wait wins; Locked is set when a lockout (not just a delay) applies.
+func CheckLoginAllowed(ctx context.Context, email, ip string) LoginThrottleResult {
+	query := `
+		SELECT
+			COALESCE(EXTRACT(EPOCH FROM (next_attempt_at - NOW())), 0)::float8,
+			COALESCE(EXTRACT(EPOCH FROM (locked_until - NOW())), 0)::float8
+		FROM login_throttles
+		WHERE (scope = 'email' AND key = $1) OR (scope = 'ip' AND key = $2)
+	`
+	rows, err := db.Pool.Query(ctx, query, NormalizeEmail(email), ip)
+	if err != nil {
+		// Fail open: throttling must never take login down with it
+		return LoginThrottleResult{}
+	}
+	defer rows.Close()
+
+	var result LoginThrottleResult
+	for rows.Next() {
+		var delaySecs, lockSecs float64
+		if err := rows.Scan(&delaySecs, &lockSecs); err != nil {
+			continue
+		}
+		if lockSecs > 0 {
+			result.Locked = true
+		}
+		wait := time.Duration(math.Max(delaySecs, lockSecs) * float64(time.Second))
+		if wait > result.RetryAfter {
+			result.RetryAfter = wait
+		}
+	}
+	return result
+}
+
+// RecordLoginFailure counts a failed attempt against the account and the IP and
+// applies the progressive delay / lockout policy. It returns which scopes were
+// locked by this failure.
+func RecordLoginFailure(ctx context.Context, email, ip string, cfg *config.Config) (accountLocked bool, ipLocked bool) {
+	emailFailures := recordFailure(ctx, "email", NormalizeEmail(email), cfg)
+	ipFailures := recordFailure(ctx, "ip", ip, cfg)
+
+	// Progressive delay per account: base, 2x base, 4x base ... capped
+	if emailFailures > 0 {
+		delay := cfg.LoginBaseDelay * time.Duration(1<<uint(min(emailFailures-1, 16)))
+		if delay > cfg.LoginMaxDelay {
+			delay = cfg.LoginMaxDelay
+		}
+		accountLocked = emailFailures >= cfg.LoginMaxFailures
+		applyPenalty(ctx, "email", NormalizeEmail(email), delay, accountLocked, cfg)
+	}
+
+	// Per IP there is no delay (offices share IPs), only a hard lockout
+	if ipFailures > 0 && ipFailures >= cfg.LoginMaxFailuresPerIP {
+		ipLocked = true
+		applyPenalty(ctx, "ip", ip, 0, true, cfg)
+	}
+
+	return accountLocked, ipLocked
+}
+
+// ResetLoginFailures clears the account's counter after a successful login
+func ResetLoginFailures(ctx context.Context, email string) {
+	_, _ = db.Pool.Exec(ctx, `DELETE FROM login_throttles WHERE scope = 'email' AND key = $1`, NormalizeEmail(email))
+}
+
+func recordFailure(ctx context.Context, scope, key string, cfg *config.Config) int {
+	query := `
+		INSERT INTO login_throttles (scope, key, failures, window_started_at, last_failure_at)
+		VALUES ($1, $2, 1, NOW(), NOW())
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failures = CASE
+				WHEN login_throttles.window_started_at < NOW() - make_interval(secs => $3) THEN 1
+				ELSE login_throttles.failures + 1
+			END,
+			window_started_at = CASE
+				WHEN login_throttles.window_started_at < NOW() - make_interval(secs => $3) THEN NOW()
+				ELSE login_throttles.window_started_at
+			END,
+			last_failure_at = NOW()
+		RETURNING failures
+	`
+	var failures int
+	err := db.Pool.QueryRow(ctx, query, scope, key, cfg.LoginFailureWindow.Seconds()).Scan(&failures)
+	if err != nil {
+		return 0
+	}
+	return failures
+}
+
+func applyPenalty(ctx context.Context, scope, key string, delay time.Duration, lock bool, cfg *config.Config) {
+	query := `
+		UPDATE login_throttles
+		SET next_attempt_at = NOW() + make_interval(secs => $3),
+		    locked_until = CASE WHEN $4 THEN NOW() + make_interval(secs => $5) ELSE locked_until END
+		WHERE scope = $1 AND key = $2
+	`
+	_, _ = db.Pool.Exec(ctx, query, scope, key, delay.Seconds(), lock, cfg.LoginLockoutDuration.Seconds())
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,15 +18,79 @@ type Config struct {
 	DBHost            string
 	DBPort            string
 	DatabaseURL       string
+	AppEnv            string
 	JWTSecret         string
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+	ImpersonationTTL  time.Duration
 	ServerPort        string
 	R2AccessKeyID     string
 	R2SecretAccessKey string
 	R2Bucket          string
 	R2Endpoint        string
 	R2PublicBaseURL   string
+	FrontendURL       string
+	MailDriver        string
+	MailFrom          string
+	MailOutboxDir     string
+	SMTPHost          string
+	SMTPPort          string
+	SMTPUsername      string
+	SMTPPassword      string
+	PasswordResetTTL  time.Duration
+
+	RequireEmailVerification   bool
+	EmailVerificationTTL       time.Duration
+	VerificationResendCooldown time.Duration
+
+	Require2FAForAdmins bool
+	TOTPIssuer          string
+
+	LoginMaxFailures      int
+	LoginMaxFailuresPerIP int
+	LoginFailureWindow    time.Duration
+	LoginLockoutDuration  time.Duration
+	LoginBaseDelay        time.Duration
+	LoginMaxDelay         time.Duration
+
+	JWTSigningAlg     string
+	JWTKeyID          string
+	JWTPrivateKey     string
+	JWTPrivateKeyPath string
+	// Extra public keys still accepted during rotation: "kid=/path.pem,kid2=/path2.pem"
+	JWTVerificationKeys string
+
+	OIDCProviders []OIDCProvider
+	OIDCStateTTL  time.Duration
+
+	AccountDeletionGracePeriod time.Duration
+
+	// Refuse (409) quote requests for dates the vendor marked booked or blocked
+	// instead of only warning
+	RefuseUnavailableQuotes bool
+
+	// Bucket for files that must never be public (verification documents).
+	// It has no public domain and is separate from R2Bucket; uploads are
+	// refused while it is unset.
+	R2PrivateBucket string
+	// Lifetime of the signed links handed out for private files
+	PrivateFileURLTTL time.Duration
+}
+
+// OIDCProvider is an external identity provider for social login. Each one is
+// configured from OIDC_<NAME>_* variables, see LoadOIDCProviders.
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
+// DefaultJWTSecret is only meant for local development
+const DefaultJWTSecret = "dev_secret_do_not_use_in_prod"
+
 func LoadConfig() *Config {
 	err := godotenv.Load()
 	if err != nil {
@@ -36,14 +104,126 @@ func LoadConfig() *Config {
 		DBHost:            getEnv("DB_HOST", "localhost"),
 		DBPort:            getEnv("DB_PORT", "5432"),
 		DatabaseURL:       getEnv("DATABASE_URL", ""),
-		JWTSecret:         getEnv("JWT_SECRET", "dev_secret_do_not_use_in_prod"),
+		AppEnv:            getEnv("APP_ENV", "development"),
+		JWTSecret:         getEnv("JWT_SECRET", DefaultJWTSecret),
+		AccessTokenTTL:    getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:   getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		ImpersonationTTL:  getEnvDuration("IMPERSONATION_TTL", 15*time.Minute),
 		ServerPort:        getEnv("SERVER_PORT", "8080"),
 		R2AccessKeyID:     getEnv("R2_ACCESS_KEY_ID", ""),
 		R2SecretAccessKey: getEnv("R2_SECRET_ACCESS_KEY", ""),
 		R2Bucket:          getEnv("R2_BUCKET", ""),
 		R2Endpoint:        getEnv("R2_ENDPOINT", ""),
 		R2PublicBaseURL:   getEnv("R2_PUBLIC_BASE_URL", ""),
+		FrontendURL:       getEnv("FRONTEND_URL", "http://localhost:3000"),
+		MailDriver:        getEnv("MAIL_DRIVER", "log"),
+		MailFrom:          getEnv("MAIL_FROM", "Bventy <no-reply@bventy.in>"),
+		MailOutboxDir:     getEnv("MAIL_OUTBOX_DIR", ""),
+		SMTPHost:          getEnv("SMTP_HOST", ""),
+		SMTPPort:          getEnv("SMTP_PORT", "587"),
+		SMTPUsername:      getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:      getEnv("SMTP_PASSWORD", ""),
+		PasswordResetTTL:  getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+
+		RequireEmailVerification:   getEnvBool("REQUIRE_EMAIL_VERIFICATION", true),
+		EmailVerificationTTL:       getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		VerificationResendCooldown: getEnvDuration("VERIFICATION_RESEND_COOLDOWN", time.Minute),
+
+		Require2FAForAdmins: getEnvBool("REQUIRE_2FA_FOR_ADMINS", false),
+		TOTPIssuer:          getEnv("TOTP_ISSUER", "Bventy"),
+
+		LoginMaxFailures:      getEnvInt("LOGIN_MAX_FAILURES", 5),
+		LoginMaxFailuresPerIP: getEnvInt("LOGIN_MAX_FAILURES_PER_IP", 50),
+		LoginFailureWindow:    getEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		LoginLockoutDuration:  getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		LoginBaseDelay:        getEnvDuration("LOGIN_BASE_DELAY", time.Second),
+		LoginMaxDelay:         getEnvDuration("LOGIN_MAX_DELAY", 30*time.Second),
+
+		JWTSigningAlg:       getEnv("JWT_SIGNING_ALG", "HS256"),
+		JWTKeyID:            getEnv("JWT_KEY_ID", ""),
+		JWTPrivateKey:       getEnv("JWT_PRIVATE_KEY", ""),
+		JWTPrivateKeyPath:   getEnv("JWT_PRIVATE_KEY_PATH", ""),
+		JWTVerificationKeys: getEnv("JWT_VERIFICATION_KEYS", ""),
+
+		OIDCProviders: loadOIDCProviders(),
+		OIDCStateTTL:  getEnvDuration("OIDC_STATE_TTL", 10*time.Minute),
+
+		AccountDeletionGracePeriod: getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 14*24*time.Hour),
+
+		RefuseUnavailableQuotes: getEnvBool("REFUSE_UNAVAILABLE_QUOTES", false),
+
+		R2PrivateBucket:   getEnv("R2_PRIVATE_BUCKET", ""),
+		PrivateFileURLTTL: getEnvDuration("PRIVATE_FILE_URL_TTL", 10*time.Minute),
+	}
+}
+
+// OIDCProvider returns the configured provider with the given name
+func (c *Config) OIDCProvider(name string) (OIDCProvider, bool) {
+	for _, p := range c.OIDCProviders {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return OIDCProvider{}, false
+}
+
+// loadOIDCProviders reads OIDC_PROVIDERS ("google,mock") and for each name the
+// OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL and _SCOPES variables.
+// Incomplete providers are skipped with a warning.
+func loadOIDCProviders() []OIDCProvider {
+	var providers []OIDCProvider
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		p := OIDCProvider{
+			Name:         name,
+			Issuer:       strings.TrimSuffix(getEnv(prefix+"ISSUER", ""), "/"),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		}
+		if p.Issuer == "" || p.ClientID == "" || p.RedirectURL == "" {
+			log.Printf("⚠️  Warning: OIDC provider %q is missing ISSUER, CLIENT_ID or REDIRECT_URL, skipping", name)
+			continue
+		}
+		providers = append(providers, p)
 	}
+	return providers
+}
+
+// IsProduction reports whether APP_ENV is set to production
+func (c *Config) IsProduction() bool {
+	return c.AppEnv == "production"
+}
+
+// Validate rejects configurations that are unsafe to run in production
+func (c *Config) Validate() error {
+	if !c.IsProduction() {
+		return nil
+	}
+	if c.JWTSigningAlg == "" || c.JWTSigningAlg == "HS256" {
+		if c.JWTSecret == DefaultJWTSecret {
+			return errors.New("JWT_SECRET must be set in production (the development default is not allowed)")
+		}
+		if len(c.JWTSecret) < 32 {
+			return errors.New("JWT_SECRET must be at least 32 characters in production")
+		}
+	}
+	// The log mailer prints reset and verification links to stdout
+	if c.MailDriver != "smtp" {
+		return errors.New("MAIL_DRIVER must be smtp in production")
+	}
+	if c.SMTPHost == "" {
+		return errors.New("SMTP_HOST must be set in production")
+	}
+	if c.R2PrivateBucket == "" || c.R2PrivateBucket == c.R2Bucket {
+		return errors.New("R2_PRIVATE_BUCKET must be set in production to a bucket other than R2_BUCKET")
+	}
+	return nil
 }
 
 func getEnv(key, fallback string) string {
@@ -52,3 +232,42 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("⚠️  Warning: invalid duration for %s (%q), using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("⚠️  Warning: invalid boolean for %s (%q), using %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("⚠️  Warning: invalid integer for %s (%q), using %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,31 @@
+-- 13. Auth Sessions & Rotating Refresh Tokens
+-- A session is one signed-in device. Every refresh rotates the token; a rotated
+-- token presented again revokes the whole session (reuse detection).
+CREATE TABLE "public"."user_sessions" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "user_id" uuid NOT NULL,
+    "user_agent" text,
+    "ip_address" text,
+    "created_at" timestamp DEFAULT now(),
+    "expires_at" timestamp NOT NULL,
+    "revoked_at" timestamp,
+    "revoked_reason" text,
+    CONSTRAINT "user_sessions_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "user_sessions_user_id_fkey" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) WITH (oids = false);
+
+CREATE INDEX idx_user_sessions_user ON public.user_sessions USING btree (user_id);
+
+CREATE TABLE "public"."refresh_tokens" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "session_id" uuid NOT NULL,
+    "token_hash" text NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "expires_at" timestamp NOT NULL,
+    "used_at" timestamp,
+    CONSTRAINT "refresh_tokens_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "refresh_tokens_token_hash_key" UNIQUE ("token_hash"),
+    CONSTRAINT "refresh_tokens_session_id_fkey" FOREIGN KEY (session_id) REFERENCES user_sessions(id) ON DELETE CASCADE
+) WITH (oids = false);
+
+CREATE INDEX idx_refresh_tokens_session ON public.refresh_tokens USING btree (session_id);
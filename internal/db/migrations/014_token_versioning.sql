@@ -0,0 +1,6 @@
+-- 14. Token Versioning & Suspension
+-- Bumping token_version invalidates every access token issued for the user,
+-- so role changes, password changes and suspensions take effect immediately.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS token_version int NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS suspended_at timestamp;
@@ -0,0 +1,16 @@
+-- 15. Password Reset Tokens
+-- Only a SHA-256 hash of the emailed token is stored; tokens are single-use.
+CREATE TABLE "public"."password_reset_tokens" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "user_id" uuid NOT NULL,
+    "token_hash" text NOT NULL,
+    "requested_ip" text,
+    "created_at" timestamp DEFAULT now(),
+    "expires_at" timestamp NOT NULL,
+    "used_at" timestamp,
+    CONSTRAINT "password_reset_tokens_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "password_reset_tokens_token_hash_key" UNIQUE ("token_hash"),
+    CONSTRAINT "password_reset_tokens_user_id_fkey" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) WITH (oids = false);
+
+CREATE INDEX idx_password_reset_tokens_user ON public.password_reset_tokens USING btree (user_id);
@@ -0,0 +1,21 @@
+-- 16. Email Verification
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at timestamp;
+
+-- Accounts created before verification existed are grandfathered in
+UPDATE users SET email_verified_at = COALESCE(created_at, now()) WHERE email_verified_at IS NULL;
+
+-- The address is snapshotted so a link only verifies the email it was sent to
+CREATE TABLE "public"."email_verification_tokens" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "user_id" uuid NOT NULL,
+    "email" text NOT NULL,
+    "token_hash" text NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "expires_at" timestamp NOT NULL,
+    "used_at" timestamp,
+    CONSTRAINT "email_verification_tokens_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "email_verification_tokens_token_hash_key" UNIQUE ("token_hash"),
+    CONSTRAINT "email_verification_tokens_user_id_fkey" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) WITH (oids = false);
+
+CREATE INDEX idx_email_verification_tokens_user ON public.email_verification_tokens USING btree (user_id);
@@ -0,0 +1,19 @@
+-- 17. TOTP Two-Factor Authentication
+-- totp_secret is set at enrollment; 2FA only counts as enabled once totp_enabled_at is set.
+-- totp_last_step stores the last accepted time step so a code cannot be replayed.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS totp_secret text,
+ADD COLUMN IF NOT EXISTS totp_enabled_at timestamp,
+ADD COLUMN IF NOT EXISTS totp_last_step bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "public"."user_recovery_codes" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "user_id" uuid NOT NULL,
+    "code_hash" text NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "used_at" timestamp,
+    CONSTRAINT "user_recovery_codes_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "user_recovery_codes_user_id_fkey" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) WITH (oids = false);
+
+CREATE INDEX idx_user_recovery_codes_user ON public.user_recovery_codes USING btree (user_id);
@@ -0,0 +1,14 @@
+-- 18. Login Throttling & Lockout
+-- One row per throttled key: scope 'email' tracks an account, scope 'ip' a client address.
+-- Failures older than the configured window start a new count.
+CREATE TABLE "public"."login_throttles" (
+    "scope" text NOT NULL,
+    "key" text NOT NULL,
+    "failures" int NOT NULL DEFAULT 0,
+    "window_started_at" timestamp NOT NULL DEFAULT now(),
+    "last_failure_at" timestamp,
+    "next_attempt_at" timestamp,
+    "locked_until" timestamp,
+    CONSTRAINT "login_throttles_pkey" PRIMARY KEY ("scope", "key"),
+    CONSTRAINT "login_throttles_scope_check" CHECK (scope IN ('email', 'ip'))
+) WITH (oids = false);
@@ -0,0 +1,41 @@
+-- 19. Granular Permissions
+-- Effective permissions = role defaults (role_permissions) + direct grants (user_permissions).
+-- super_admin bypasses permission checks entirely.
+ALTER TABLE permissions ADD COLUMN IF NOT EXISTS description text;
+
+CREATE TABLE "public"."role_permissions" (
+    "role" text NOT NULL,
+    "permission_id" uuid NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    CONSTRAINT "role_permissions_pkey" PRIMARY KEY ("role", "permission_id"),
+    CONSTRAINT "role_permissions_permission_id_fkey" FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE,
+    CONSTRAINT "role_permissions_role_check" CHECK (role IN ('user', 'staff', 'admin', 'super_admin'))
+) WITH (oids = false);
+
+ALTER TABLE user_permissions ADD COLUMN IF NOT EXISTS granted_by uuid REFERENCES users(id) ON DELETE SET NULL;
+
+INSERT INTO permissions (code, description) VALUES
+('metrics.view', 'View dashboard stats and analytics'),
+('vendor.view', 'List vendor profiles in moderation'),
+('vendor.verify', 'Approve or reject vendor profiles'),
+('users.view', 'List user accounts'),
+('users.manage', 'Suspend, unsuspend and unlock user accounts'),
+('users.role', 'Change user roles'),
+('permissions.manage', 'Grant and revoke permissions')
+ON CONFLICT (code) DO UPDATE SET description = EXCLUDED.description;
+
+-- Staff moderate vendors; admins run the platform. Role and permission
+-- management stay with super_admin unless granted explicitly.
+INSERT INTO role_permissions (role, permission_id)
+SELECT r.role, p.id
+FROM (VALUES
+    ('staff', 'vendor.view'),
+    ('staff', 'vendor.verify'),
+    ('admin', 'metrics.view'),
+    ('admin', 'vendor.view'),
+    ('admin', 'vendor.verify'),
+    ('admin', 'users.view'),
+    ('admin', 'users.manage')
+) AS r(role, code)
+JOIN permissions p ON p.code = r.code
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,22 @@
+-- 20. Impersonation
+-- Impersonation tokens are bound to a dedicated session of the impersonated user
+-- so they can be revoked like any other session.
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS impersonator_id uuid REFERENCES users(id) ON DELETE CASCADE;
+
+-- One row per request made with an impersonation token
+CREATE TABLE "public"."impersonation_audit_log" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "session_id" uuid NOT NULL,
+    "impersonator_id" uuid NOT NULL,
+    "impersonated_user_id" uuid NOT NULL,
+    "method" text NOT NULL,
+    "path" text NOT NULL,
+    "status" int,
+    "blocked" boolean NOT NULL DEFAULT false,
+    "ip_address" text,
+    "created_at" timestamp DEFAULT now(),
+    CONSTRAINT "impersonation_audit_log_pkey" PRIMARY KEY ("id")
+) WITH (oids = false);
+
+CREATE INDEX idx_impersonation_audit_impersonator ON public.impersonation_audit_log USING btree (impersonator_id);
+CREATE INDEX idx_impersonation_audit_session ON public.impersonation_audit_log USING btree (session_id);
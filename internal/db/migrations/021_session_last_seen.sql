@@ -0,0 +1,4 @@
+-- 21. Session Activity
+-- last_seen_at is written at most once per minute per session (see auth.TouchSession)
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS last_seen_at timestamp DEFAULT now();
+UPDATE user_sessions SET last_seen_at = created_at WHERE last_seen_at IS NULL;
@@ -0,0 +1,20 @@
+-- 22. Email Change Requests
+-- The new address must be confirmed before users.email changes. session_id is
+-- the session that asked for the change; it survives when others are revoked.
+CREATE TABLE "public"."email_change_requests" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "user_id" uuid NOT NULL,
+    "session_id" uuid,
+    "old_email" text NOT NULL,
+    "new_email" text NOT NULL,
+    "token_hash" text NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "expires_at" timestamp NOT NULL,
+    "confirmed_at" timestamp,
+    "cancelled_at" timestamp,
+    CONSTRAINT "email_change_requests_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "email_change_requests_token_hash_key" UNIQUE ("token_hash"),
+    CONSTRAINT "email_change_requests_user_id_fkey" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) WITH (oids = false);
+
+CREATE INDEX idx_email_change_requests_user ON public.email_change_requests USING btree (user_id);
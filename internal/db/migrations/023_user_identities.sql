@@ -0,0 +1,31 @@
+-- 23. External Identities (OpenID Connect)
+-- A user can sign in with a password, with any linked provider, or both.
+-- Accounts created through a provider start with an empty password_hash,
+-- which never matches; they can set one via the password reset flow.
+CREATE TABLE "public"."user_identities" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "user_id" uuid NOT NULL,
+    "provider" text NOT NULL,
+    "subject" text NOT NULL,
+    "email" text,
+    "created_at" timestamp DEFAULT now(),
+    "last_login_at" timestamp,
+    CONSTRAINT "user_identities_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "user_identities_provider_subject_key" UNIQUE ("provider", "subject"),
+    CONSTRAINT "user_identities_user_provider_key" UNIQUE ("user_id", "provider"),
+    CONSTRAINT "user_identities_user_id_fkey" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) WITH (oids = false);
+
+-- Pending authorization requests: state is single-use and carries the PKCE verifier and nonce
+CREATE TABLE "public"."oidc_login_states" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "state_hash" text NOT NULL,
+    "provider" text NOT NULL,
+    "code_verifier" text NOT NULL,
+    "nonce" text NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "expires_at" timestamp NOT NULL,
+    "used_at" timestamp,
+    CONSTRAINT "oidc_login_states_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "oidc_login_states_state_hash_key" UNIQUE ("state_hash")
+) WITH (oids = false);
@@ -0,0 +1,21 @@
+-- 24. API Keys
+-- Keys look like bv_<prefix>_<secret>. The prefix is stored in clear so a key
+-- can be identified in lists and logs; only a SHA-256 hash of the full key is kept.
+CREATE TABLE "public"."api_keys" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "user_id" uuid NOT NULL,
+    "name" text NOT NULL,
+    "prefix" text NOT NULL,
+    "key_hash" text NOT NULL,
+    "scopes" text[] NOT NULL DEFAULT '{}',
+    "created_at" timestamp DEFAULT now(),
+    "expires_at" timestamp,
+    "last_used_at" timestamp,
+    "last_used_ip" text,
+    "revoked_at" timestamp,
+    CONSTRAINT "api_keys_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "api_keys_prefix_key" UNIQUE ("prefix"),
+    CONSTRAINT "api_keys_user_id_fkey" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) WITH (oids = false);
+
+CREATE INDEX idx_api_keys_user ON public.api_keys USING btree (user_id);
@@ -0,0 +1,13 @@
+-- 25. Account Deletion
+-- DELETE /me schedules deletion; after the grace period the account is
+-- anonymized in place (the row stays so quotes other parties rely on keep
+-- their organizer/vendor reference) and everything else is removed.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_requested_at timestamp;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_for timestamp;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled ON public.users USING btree (deletion_scheduled_for) WHERE deleted_at IS NULL;
+
+-- Vendor profiles of deleted accounts are hidden but kept for their quotes
+ALTER TABLE vendor_profiles DROP CONSTRAINT IF EXISTS vendor_profiles_status_check;
+ALTER TABLE vendor_profiles ADD CONSTRAINT vendor_profiles_status_check CHECK (status IN ('pending', 'verified', 'rejected', 'deleted'));
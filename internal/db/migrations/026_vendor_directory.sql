@@ -0,0 +1,56 @@
+-- 26. Vendor Directory
+-- Popularity counters are denormalized onto vendor_profiles so the directory
+-- can sort and paginate on them with an index instead of aggregating per request.
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS shortlist_count int NOT NULL DEFAULT 0;
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS view_count bigint NOT NULL DEFAULT 0;
+
+UPDATE vendor_profiles SET created_at = now() WHERE created_at IS NULL;
+
+UPDATE vendor_profiles vp SET shortlist_count = s.n
+FROM (SELECT vendor_id, COUNT(*) AS n FROM event_shortlisted_vendors GROUP BY vendor_id) s
+WHERE s.vendor_id = vp.id;
+
+UPDATE vendor_profiles vp SET view_count = v.n
+FROM (
+    SELECT entity_id::text AS vendor_id, COUNT(*) AS n FROM platform_activity_log
+    WHERE entity_type = 'vendor' AND action_type = 'view'
+    GROUP BY entity_id::text
+) v
+WHERE v.vendor_id = vp.id::text;
+
+CREATE OR REPLACE FUNCTION vendor_shortlist_count_trg() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE vendor_profiles SET shortlist_count = shortlist_count + 1 WHERE id = NEW.vendor_id;
+        RETURN NEW;
+    END IF;
+    UPDATE vendor_profiles SET shortlist_count = GREATEST(shortlist_count - 1, 0) WHERE id = OLD.vendor_id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_vendor_shortlist_count ON event_shortlisted_vendors;
+CREATE TRIGGER trg_vendor_shortlist_count
+    AFTER INSERT OR DELETE ON event_shortlisted_vendors
+    FOR EACH ROW EXECUTE FUNCTION vendor_shortlist_count_trg();
+
+CREATE OR REPLACE FUNCTION vendor_view_count_trg() RETURNS trigger AS $$
+BEGIN
+    IF NEW.entity_type = 'vendor' AND NEW.action_type = 'view' THEN
+        UPDATE vendor_profiles SET view_count = view_count + 1 WHERE id::text = NEW.entity_id::text;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_vendor_view_count ON platform_activity_log;
+CREATE TRIGGER trg_vendor_view_count
+    AFTER INSERT ON platform_activity_log
+    FOR EACH ROW EXECUTE FUNCTION vendor_view_count_trg();
+
+-- Directory indexes: one per sort order (keyset pagination) plus the filters
+CREATE INDEX IF NOT EXISTS idx_vendor_dir_newest ON public.vendor_profiles USING btree (created_at DESC, id DESC) WHERE status = 'verified';
+CREATE INDEX IF NOT EXISTS idx_vendor_dir_shortlisted ON public.vendor_profiles USING btree (shortlist_count DESC, id DESC) WHERE status = 'verified';
+CREATE INDEX IF NOT EXISTS idx_vendor_dir_viewed ON public.vendor_profiles USING btree (view_count DESC, id DESC) WHERE status = 'verified';
+CREATE INDEX IF NOT EXISTS idx_vendor_dir_category ON public.vendor_profiles USING btree (lower(category)) WHERE status = 'verified';
+CREATE INDEX IF NOT EXISTS idx_vendor_dir_city ON public.vendor_profiles USING btree (lower(city)) WHERE status = 'verified';
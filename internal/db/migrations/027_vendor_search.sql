@@ -0,0 +1,19 @@
+-- 27. Vendor Search
+-- search_vector drives ranked full-text search (name > category > city > bio);
+-- search_text backs typo-tolerant trigram matching. Both are generated columns,
+-- so every insert and UpdateVendor keeps them current without application code.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(business_name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(category, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(city, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(bio, '')), 'D')
+) STORED;
+
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS search_text text GENERATED ALWAYS AS (
+    lower(coalesce(business_name, '') || ' ' || coalesce(category, '') || ' ' || coalesce(city, ''))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_vendor_search_vector ON public.vendor_profiles USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS idx_vendor_search_trgm ON public.vendor_profiles USING gin (search_text gin_trgm_ops);
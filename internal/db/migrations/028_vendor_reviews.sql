@@ -0,0 +1,92 @@
+-- 28. Vendor Reviews
+-- One review per accepted quote, written by the organizer once the event is
+-- completed. Reported reviews stay visible until a moderator hides them.
+CREATE TABLE "public"."vendor_reviews" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "quote_id" uuid NOT NULL,
+    "vendor_id" uuid NOT NULL,
+    "event_id" uuid,
+    "reviewer_user_id" uuid,
+    "rating" smallint NOT NULL,
+    "body" text NOT NULL,
+    "vendor_reply" text,
+    "vendor_replied_at" timestamp,
+    "status" text DEFAULT 'published' NOT NULL,
+    "hidden_reason" text,
+    "moderated_by" uuid,
+    "moderated_at" timestamp,
+    "created_at" timestamp DEFAULT now(),
+    "updated_at" timestamp DEFAULT now(),
+    CONSTRAINT "vendor_reviews_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "vendor_reviews_quote_id_key" UNIQUE ("quote_id"),
+    CONSTRAINT "vendor_reviews_quote_id_fkey" FOREIGN KEY (quote_id) REFERENCES quote_requests(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_reviews_vendor_id_fkey" FOREIGN KEY (vendor_id) REFERENCES vendor_profiles(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_reviews_event_id_fkey" FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE SET NULL,
+    CONSTRAINT "vendor_reviews_reviewer_user_id_fkey" FOREIGN KEY (reviewer_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT "vendor_reviews_moderated_by_fkey" FOREIGN KEY (moderated_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT "vendor_reviews_rating_check" CHECK (rating BETWEEN 1 AND 5),
+    CONSTRAINT "vendor_reviews_status_check" CHECK (status IN ('published', 'hidden'))
+) WITH (oids = false);
+
+CREATE INDEX idx_vendor_reviews_vendor ON public.vendor_reviews USING btree (vendor_id, created_at DESC) WHERE status = 'published';
+CREATE INDEX idx_vendor_reviews_reviewer ON public.vendor_reviews USING btree (reviewer_user_id);
+
+CREATE TABLE "public"."vendor_review_reports" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "review_id" uuid NOT NULL,
+    "reporter_user_id" uuid NOT NULL,
+    "reason" text NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "resolved_at" timestamp,
+    "resolved_by" uuid,
+    "resolution" text,
+    CONSTRAINT "vendor_review_reports_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "vendor_review_reports_review_reporter_key" UNIQUE ("review_id", "reporter_user_id"),
+    CONSTRAINT "vendor_review_reports_review_id_fkey" FOREIGN KEY (review_id) REFERENCES vendor_reviews(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_review_reports_reporter_user_id_fkey" FOREIGN KEY (reporter_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_review_reports_resolved_by_fkey" FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT "vendor_review_reports_resolution_check" CHECK (resolution IN ('hidden', 'dismissed'))
+) WITH (oids = false);
+
+CREATE INDEX idx_vendor_review_reports_open ON public.vendor_review_reports USING btree (review_id) WHERE resolved_at IS NULL;
+
+-- Aggregates are denormalized like shortlist_count/view_count (see 026) and
+-- only count published reviews.
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS rating_count int NOT NULL DEFAULT 0;
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS rating_average numeric(3,2);
+
+CREATE OR REPLACE FUNCTION vendor_rating_trg() RETURNS trigger AS $$
+DECLARE
+    vid uuid;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        vid := OLD.vendor_id;
+    ELSE
+        vid := NEW.vendor_id;
+    END IF;
+    UPDATE vendor_profiles vp SET
+        rating_count = r.n,
+        rating_average = r.avg
+    FROM (
+        SELECT COUNT(*) AS n, ROUND(AVG(rating), 2) AS avg
+        FROM vendor_reviews WHERE vendor_id = vid AND status = 'published'
+    ) r
+    WHERE vp.id = vid;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_vendor_rating ON vendor_reviews;
+CREATE TRIGGER trg_vendor_rating
+    AFTER INSERT OR DELETE OR UPDATE OF rating, status ON vendor_reviews
+    FOR EACH ROW EXECUTE FUNCTION vendor_rating_trg();
+
+INSERT INTO permissions (code, description) VALUES
+('reviews.moderate', 'Review reported vendor reviews and hide or restore them')
+ON CONFLICT (code) DO UPDATE SET description = EXCLUDED.description;
+
+INSERT INTO role_permissions (role, permission_id)
+SELECT r.role, p.id
+FROM (VALUES ('staff', 'reviews.moderate'), ('admin', 'reviews.moderate')) AS r(role, code)
+JOIN permissions p ON p.code = r.code
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,27 @@
+-- 29. Vendor Availability
+-- One row per vendor per date that is not freely available. Dates without a
+-- row are open. Rows created from an accepted quote carry its quote_id.
+CREATE TABLE "public"."vendor_availability" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "vendor_id" uuid NOT NULL,
+    "date" date NOT NULL,
+    "status" text NOT NULL,
+    "note" text,
+    "quote_id" uuid,
+    "created_at" timestamp DEFAULT now(),
+    "updated_at" timestamp DEFAULT now(),
+    CONSTRAINT "vendor_availability_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "vendor_availability_vendor_date_key" UNIQUE ("vendor_id", "date"),
+    CONSTRAINT "vendor_availability_vendor_id_fkey" FOREIGN KEY (vendor_id) REFERENCES vendor_profiles(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_availability_quote_id_fkey" FOREIGN KEY (quote_id) REFERENCES quote_requests(id) ON DELETE SET NULL,
+    CONSTRAINT "vendor_availability_status_check" CHECK (status IN ('booked', 'blocked', 'tentative'))
+) WITH (oids = false);
+
+-- Bookings from quotes accepted before this migration
+INSERT INTO vendor_availability (vendor_id, date, status, quote_id)
+SELECT DISTINCT ON (qr.vendor_id, e.event_date::date) qr.vendor_id, e.event_date::date, 'booked', qr.id
+FROM quote_requests qr
+JOIN events e ON e.id = qr.event_id
+WHERE qr.accepted_at IS NOT NULL AND e.event_date IS NOT NULL AND e.event_date >= CURRENT_DATE
+ORDER BY qr.vendor_id, e.event_date::date, qr.accepted_at
+ON CONFLICT (vendor_id, date) DO NOTHING;
@@ -0,0 +1,26 @@
+-- 30. Vendor Service Packages
+-- Priced offerings shown on the public profile. A quote request can start from
+-- a package; the quote keeps a snapshot so later edits don't change what the
+-- organizer asked for.
+CREATE TABLE "public"."vendor_packages" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "vendor_id" uuid NOT NULL,
+    "name" text NOT NULL,
+    "description" text,
+    "inclusions" text[] DEFAULT '{}' NOT NULL,
+    "starting_price" numeric(12,2) NOT NULL,
+    "unit" text NOT NULL,
+    "is_active" boolean DEFAULT true NOT NULL,
+    "sort_order" int DEFAULT 0 NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    "updated_at" timestamp DEFAULT now(),
+    CONSTRAINT "vendor_packages_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "vendor_packages_vendor_id_fkey" FOREIGN KEY (vendor_id) REFERENCES vendor_profiles(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_packages_price_check" CHECK (starting_price >= 0),
+    CONSTRAINT "vendor_packages_unit_check" CHECK (unit IN ('per_event', 'per_plate', 'per_person', 'per_hour', 'per_day'))
+) WITH (oids = false);
+
+CREATE INDEX idx_vendor_packages_vendor ON public.vendor_packages USING btree (vendor_id, sort_order);
+
+ALTER TABLE quote_requests ADD COLUMN IF NOT EXISTS package_id uuid REFERENCES vendor_packages(id) ON DELETE SET NULL;
+ALTER TABLE quote_requests ADD COLUMN IF NOT EXISTS package_snapshot jsonb;
@@ -0,0 +1,15 @@
+-- 31. Vendor Slug History
+-- Retired slugs keep resolving to their vendor so old links can be redirected.
+-- A slug is either some vendor's current slug or in history, never both for
+-- different vendors; a vendor may take back one of its own old slugs.
+CREATE TABLE "public"."vendor_slug_history" (
+    "slug" text NOT NULL,
+    "vendor_id" uuid NOT NULL,
+    "retired_at" timestamp DEFAULT now(),
+    CONSTRAINT "vendor_slug_history_pkey" PRIMARY KEY ("slug"),
+    CONSTRAINT "vendor_slug_history_vendor_id_fkey" FOREIGN KEY (vendor_id) REFERENCES vendor_profiles(id) ON DELETE CASCADE
+) WITH (oids = false);
+
+CREATE INDEX idx_vendor_slug_history_vendor ON public.vendor_slug_history USING btree (vendor_id);
+
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS slug_changed_at timestamp;
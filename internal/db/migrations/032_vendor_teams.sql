@@ -0,0 +1,61 @@
+-- 32. Vendor Teams
+-- Several users can work on one vendor profile. owner_user_id stays the
+-- account that owns the business; everyone, the owner included, has a row in
+-- vendor_team_members. A user belongs to at most one vendor team.
+CREATE TABLE "public"."vendor_team_members" (
+    "vendor_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "role" text NOT NULL,
+    "invited_by" uuid,
+    "created_at" timestamp DEFAULT now(),
+    "updated_at" timestamp DEFAULT now(),
+    CONSTRAINT "vendor_team_members_pkey" PRIMARY KEY ("vendor_id", "user_id"),
+    CONSTRAINT "vendor_team_members_user_id_key" UNIQUE ("user_id"),
+    CONSTRAINT "vendor_team_members_vendor_id_fkey" FOREIGN KEY (vendor_id) REFERENCES vendor_profiles(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_team_members_user_id_fkey" FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_team_members_invited_by_fkey" FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT "vendor_team_members_role_check" CHECK (role IN ('owner', 'manager', 'responder'))
+) WITH (oids = false);
+
+-- Invitations are accepted with the emailed token by a signed-in user whose
+-- email matches invited_email.
+CREATE TABLE "public"."vendor_team_invitations" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "vendor_id" uuid NOT NULL,
+    "invited_email" text NOT NULL,
+    "role" text NOT NULL,
+    "token_hash" text NOT NULL,
+    "invited_by" uuid,
+    "created_at" timestamp DEFAULT now(),
+    "expires_at" timestamp NOT NULL,
+    "accepted_at" timestamp,
+    "accepted_by" uuid,
+    "revoked_at" timestamp,
+    CONSTRAINT "vendor_team_invitations_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "vendor_team_invitations_token_hash_key" UNIQUE ("token_hash"),
+    CONSTRAINT "vendor_team_invitations_vendor_id_fkey" FOREIGN KEY (vendor_id) REFERENCES vendor_profiles(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_team_invitations_invited_by_fkey" FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT "vendor_team_invitations_accepted_by_fkey" FOREIGN KEY (accepted_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT "vendor_team_invitations_role_check" CHECK (role IN ('manager', 'responder'))
+) WITH (oids = false);
+
+CREATE INDEX idx_vendor_team_invitations_vendor ON public.vendor_team_invitations USING btree (vendor_id);
+CREATE INDEX idx_vendor_team_invitations_email ON public.vendor_team_invitations USING btree (lower(invited_email));
+
+-- Existing vendors get their owner as the first member
+INSERT INTO vendor_team_members (vendor_id, user_id, role)
+SELECT id, owner_user_id, 'owner' FROM vendor_profiles
+ON CONFLICT DO NOTHING;
+
+-- New vendors too
+CREATE OR REPLACE FUNCTION vendor_owner_member_trg() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO vendor_team_members (vendor_id, user_id, role) VALUES (NEW.id, NEW.owner_user_id, 'owner');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_vendor_owner_member ON vendor_profiles;
+CREATE TRIGGER trg_vendor_owner_member
+    AFTER INSERT ON vendor_profiles
+    FOR EACH ROW EXECUTE FUNCTION vendor_owner_member_trg();
@@ -0,0 +1,41 @@
+-- 33. Vendor Service Areas
+-- Where a vendor works, beyond the single city on the profile. An area with
+-- coordinates serves everything within radius_km of it; one without is matched
+-- by city name only. Events can carry coordinates for radius-based discovery.
+CREATE TABLE "public"."vendor_service_areas" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "vendor_id" uuid NOT NULL,
+    "city" text NOT NULL,
+    "latitude" double precision,
+    "longitude" double precision,
+    "radius_km" integer DEFAULT 0 NOT NULL,
+    "sort_order" integer DEFAULT 0 NOT NULL,
+    "created_at" timestamp DEFAULT now(),
+    CONSTRAINT "vendor_service_areas_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "vendor_service_areas_vendor_id_fkey" FOREIGN KEY (vendor_id) REFERENCES vendor_profiles(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_service_areas_coordinates_check" CHECK (
+        (latitude IS NULL AND longitude IS NULL) OR
+        (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180)
+    ),
+    CONSTRAINT "vendor_service_areas_radius_check" CHECK (radius_km BETWEEN 0 AND 500)
+) WITH (oids = false);
+
+CREATE INDEX idx_vendor_service_areas_vendor ON public.vendor_service_areas USING btree (vendor_id);
+CREATE INDEX idx_vendor_service_areas_city ON public.vendor_service_areas USING btree (lower(city));
+CREATE INDEX idx_vendor_service_areas_coordinates ON public.vendor_service_areas USING btree (latitude, longitude) WHERE latitude IS NOT NULL;
+
+-- Every vendor starts out serving its profile city
+INSERT INTO vendor_service_areas (vendor_id, city)
+SELECT id, city FROM vendor_profiles WHERE coalesce(city, '') != '';
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS latitude double precision;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS longitude double precision;
+
+-- Great-circle (haversine) distance in kilometres
+CREATE OR REPLACE FUNCTION distance_km(lat1 double precision, lng1 double precision, lat2 double precision, lng2 double precision)
+RETURNS double precision AS $$
+    SELECT 2 * 6371 * asin(least(1, sqrt(
+        power(sin(radians(lat2 - lat1) / 2), 2) +
+        cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lng2 - lng1) / 2), 2)
+    )))
+$$ LANGUAGE sql IMMUTABLE STRICT;
@@ -0,0 +1,53 @@
+-- 34. Vendor Verification (KYC)
+-- Vendors upload documents to private storage and submit them for review. An
+-- admin approves or rejects with a reason; a rejected vendor can replace
+-- documents and resubmit. Retention: while the owner's account exists,
+-- documents a vendor replaces are only soft-deleted so every past decision
+-- can still be traced to what was reviewed. When the owner's account is
+-- deleted, the documents (rows and stored files) and the review history are
+-- deleted with it.
+CREATE TABLE "public"."vendor_kyc_documents" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "vendor_id" uuid NOT NULL,
+    "document_type" text NOT NULL,
+    "storage_key" text NOT NULL,
+    "file_name" text NOT NULL,
+    "content_type" text NOT NULL,
+    "size_bytes" bigint NOT NULL,
+    "uploaded_by" uuid,
+    "created_at" timestamp DEFAULT now(),
+    "removed_at" timestamp,
+    CONSTRAINT "vendor_kyc_documents_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "vendor_kyc_documents_vendor_id_fkey" FOREIGN KEY (vendor_id) REFERENCES vendor_profiles(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_kyc_documents_uploaded_by_fkey" FOREIGN KEY (uploaded_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT "vendor_kyc_documents_type_check" CHECK (document_type IN ('gst_certificate', 'business_registration', 'id_proof', 'address_proof', 'other'))
+) WITH (oids = false);
+
+CREATE INDEX idx_vendor_kyc_documents_vendor ON public.vendor_kyc_documents USING btree (vendor_id, created_at);
+
+-- One row per submission and per decision. document_ids is what was active
+-- at that moment.
+CREATE TABLE "public"."vendor_kyc_reviews" (
+    "id" uuid DEFAULT uuid_generate_v4() NOT NULL,
+    "vendor_id" uuid NOT NULL,
+    "action" text NOT NULL,
+    "reason" text,
+    "document_ids" uuid[] DEFAULT '{}' NOT NULL,
+    "actor_user_id" uuid,
+    "created_at" timestamp DEFAULT now(),
+    CONSTRAINT "vendor_kyc_reviews_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "vendor_kyc_reviews_vendor_id_fkey" FOREIGN KEY (vendor_id) REFERENCES vendor_profiles(id) ON DELETE CASCADE,
+    CONSTRAINT "vendor_kyc_reviews_actor_user_id_fkey" FOREIGN KEY (actor_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT "vendor_kyc_reviews_action_check" CHECK (action IN ('submitted', 'approved', 'rejected')),
+    CONSTRAINT "vendor_kyc_reviews_reason_check" CHECK (action != 'rejected' OR coalesce(reason, '') != '')
+) WITH (oids = false);
+
+CREATE INDEX idx_vendor_kyc_reviews_vendor ON public.vendor_kyc_reviews USING btree (vendor_id, created_at);
+
+-- kyc_submitted_at is set while a submission awaits review
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS kyc_submitted_at timestamp;
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS rejection_reason text;
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS reviewed_at timestamp;
+ALTER TABLE vendor_profiles ADD COLUMN IF NOT EXISTS reviewed_by uuid REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_vendor_kyc_queue ON public.vendor_profiles USING btree (kyc_submitted_at) WHERE status = 'pending' AND kyc_submitted_at IS NOT NULL;
@@ -0,0 +1,9 @@
+-- 35. Vendor Directory Text Filter
+-- The directory's q filter is a substring match over name, category, city and
+-- bio. A trigram index over the same expression (see vendorDirectoryTextSQL)
+-- serves it; the unit separator keeps a match from spanning two fields.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_vendor_dir_text ON public.vendor_profiles USING gin ((
+    lower(coalesce(business_name, '') || E'\x1f' || coalesce(category, '') || E'\x1f' || coalesce(city, '') || E'\x1f' || coalesce(bio, ''))
+) gin_trgm_ops) WHERE status = 'verified';
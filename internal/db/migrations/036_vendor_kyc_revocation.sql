@@ -0,0 +1,10 @@
+-- 36. Vendor Verification Revocation
+-- An admin can withdraw a vendor's verification with a reason, e.g. when a
+-- document turns out to be forged. The vendor goes back to 'rejected' and can
+-- resubmit. Vendors left 'pending' from before KYC have no submission; admins
+-- can still approve or reject them directly.
+ALTER TABLE vendor_kyc_reviews DROP CONSTRAINT IF EXISTS vendor_kyc_reviews_action_check;
+ALTER TABLE vendor_kyc_reviews ADD CONSTRAINT vendor_kyc_reviews_action_check CHECK (action IN ('submitted', 'approved', 'rejected', 'revoked'));
+
+ALTER TABLE vendor_kyc_reviews DROP CONSTRAINT IF EXISTS vendor_kyc_reviews_reason_check;
+ALTER TABLE vendor_kyc_reviews ADD CONSTRAINT vendor_kyc_reviews_reason_check CHECK (action NOT IN ('rejected', 'revoked') OR coalesce(reason, '') != '');
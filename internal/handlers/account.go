@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/auth"
+	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/mailer"
+	"github.com/gin-gonic/gin"
+	pgx "github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// PUT /me/password
+// Requires the current password. Other sessions are signed out; this one gets a fresh token.
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	sessionID := c.GetString("sessionID")
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	var passwordHash, email string
+	if err := db.Pool.QueryRow(ctx, `SELECT password_hash, email FROM users WHERE id = $1`, userID).Scan(&passwordHash, &email); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !h.checkCurrentPassword(c, userID, email, passwordHash, req.CurrentPassword) {
+		return
+	}
+	if req.CurrentPassword == req.NewPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New password must be different from the current one"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	var role string
+	var version int
+	updateQuery := `
+		UPDATE users SET password_hash = $1, token_version = token_version + 1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING role, token_version
+	`
+	if err := db.Pool.QueryRow(ctx, updateQuery, string(hashedPassword), userID).Scan(&role, &version); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	if err := auth.RevokeUserSessions(ctx, userID, sessionID, "password_changed"); err != nil {
+		log.Printf("ERROR: Failed to revoke sessions after password change: %v", err)
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'password_changed', $1)`, userID)
+
+	mailer.SendAsync(h.Mailer, mailer.Message{
+		To:      email,
+		Subject: "Your Bventy password was changed",
+		Body: "The password for your Bventy account was just changed and your other devices were signed out.\n\n" +
+			"If this wasn't you, reset your password immediately at " + h.Config.FrontendURL + "/forgot-password",
+	})
+
+	// The version bump invalidated the caller's token too; hand back a replacement
+	accessToken, err := auth.GenerateToken(userID, role, sessionID, version, h.Config)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Password updated. Please refresh your session."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Password updated",
+		"token":      accessToken,
+		"expires_in": int64(h.Config.AccessTokenTTL.Seconds()),
+	})
+}
+
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+// POST /me/email
+// Starts an email change: the new address gets a confirmation link, the old one a heads-up.
+func (h *AuthHandler) RequestEmailChange(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	sessionID := c.GetString("sessionID")
+
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	newEmail := strings.TrimSpace(req.NewEmail)
+
+	ctx := context.Background()
+
+	var passwordHash, oldEmail string
+	if err := db.Pool.QueryRow(ctx, `SELECT password_hash, email FROM users WHERE id = $1`, userID).Scan(&passwordHash, &oldEmail); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !h.checkCurrentPassword(c, userID, oldEmail, passwordHash, req.CurrentPassword) {
+		return
+	}
+	if strings.EqualFold(newEmail, oldEmail) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This is already your email address"})
+		return
+	}
+
+	// A taken address gets the same response as a free one, so this cannot be
+	// used to find out who has an account. Its owner hears about it instead.
+	var taken bool
+	_ = db.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE lower(email) = lower($1))`, newEmail).Scan(&taken)
+	if taken {
+		mailer.SendAsync(h.Mailer, mailer.Message{
+			To:      newEmail,
+			Subject: "Someone tried to use your email address on Bventy",
+			Body: "Someone asked to move another Bventy account to this email address. It is already used by your account, so nothing was changed.\n\n" +
+				"If this was you, sign in with this address instead. Otherwise you can ignore this email.",
+		})
+		c.JSON(http.StatusOK, gin.H{"message": emailChangeStartedMessage})
+		return
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	// Only the latest request can be confirmed
+	_, _ = db.Pool.Exec(ctx, `UPDATE email_change_requests SET cancelled_at = NOW() WHERE user_id = $1 AND confirmed_at IS NULL AND cancelled_at IS NULL`, userID)
+
+	var sessionArg interface{} = sessionID
+	if sessionID == "" {
+		sessionArg = nil
+	}
+	insertQuery := `
+		INSERT INTO email_change_requests (user_id, session_id, old_email, new_email, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err = db.Pool.Exec(ctx, insertQuery, userID, sessionArg, oldEmail, newEmail, tokenHash, time.Now().Add(h.Config.EmailVerificationTTL))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start email change"})
+		return
+	}
+
+	metadata := map[string]interface{}{"new_email": newEmail}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'email_change_requested', $1, $2)`, userID, metadata)
+
+	link := h.Config.FrontendURL + "/confirm-email-change?token=" + url.QueryEscape(token)
+	mailer.SendAsync(h.Mailer, mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new Bventy email address",
+		Body: "Please confirm that you want to use this address for your Bventy account:\n" + link + "\n\n" +
+			"If you did not request this, you can ignore this email.",
+	})
+	mailer.SendAsync(h.Mailer, mailer.Message{
+		To:      oldEmail,
+		Subject: "Your Bventy email address is being changed",
+		Body: "A request was made to change the email address on your Bventy account to " + maskEmail(newEmail) + ".\n\n" +
+			"Nothing changes until the new address is confirmed. If this wasn't you, reset your password at " +
+			h.Config.FrontendURL + "/forgot-password",
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": emailChangeStartedMessage})
+}
+
+const emailChangeStartedMessage = "Check your new inbox to confirm the change"
+
+// checkCurrentPassword verifies a password re-entered for a sensitive change.
+// It shares Login's throttle so a stolen token cannot be used to guess the
+// password, and writes the error response itself when the check fails.
+func (h *AuthHandler) checkCurrentPassword(c *gin.Context, userID, email, passwordHash, password string) bool {
+	ctx := context.Background()
+
+	throttle := auth.CheckLoginAllowed(ctx, email, c.ClientIP())
+	if !throttle.Allowed() {
+		respondLoginThrottled(c, throttle)
+		return false
+	}
+	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)) != nil {
+		h.recordLoginFailure(c, email, userID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return false
+	}
+
+	auth.ResetLoginFailures(ctx, email)
+	return true
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// POST /auth/confirm-email-change
+func (h *AuthHandler) ConfirmEmailChange(c *gin.Context) {
+	var req ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var requestID, userID, oldEmail, newEmail string
+	var sessionID *string
+	requestQuery := `
+		SELECT id, user_id, session_id::text, old_email, new_email FROM email_change_requests
+		WHERE token_hash = $1 AND confirmed_at IS NULL AND cancelled_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`
+	err = tx.QueryRow(ctx, requestQuery, auth.HashToken(req.Token)).Scan(&requestID, &userID, &sessionID, &oldEmail, &newEmail)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Confirmation link is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate confirmation token"})
+		return
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE email_change_requests SET confirmed_at = NOW() WHERE id = $1`, requestID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to consume confirmation token"})
+		return
+	}
+
+	// The account must still be on the address the change was requested from
+	updateQuery := `
+		UPDATE users SET email = $1, email_verified_at = NOW(), token_version = token_version + 1, updated_at = NOW()
+		WHERE id = $2 AND email = $3
+		RETURNING id
+	`
+	var id string
+	err = tx.QueryRow(ctx, updateQuery, newEmail, userID, oldEmail).Scan(&id)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Confirmation link no longer matches your account"})
+		return
+	}
+	if err != nil && strings.Contains(err.Error(), "unique constraint") {
+		// Someone registered the address in the meantime. Answer like a stale
+		// link rather than confirming the address has an account.
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Confirmation link is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update email"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	keep := ""
+	if sessionID != nil {
+		keep = *sessionID
+	}
+	if err := auth.RevokeUserSessions(ctx, userID, keep, "email_changed"); err != nil {
+		log.Printf("ERROR: Failed to revoke sessions after email change: %v", err)
+	}
+
+	metadata := map[string]interface{}{"old_email": oldEmail, "new_email": newEmail}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'email_changed', $1, $2)`, userID, metadata)
+
+	mailer.SendAsync(h.Mailer, mailer.Message{
+		To:      oldEmail,
+		Subject: "Your Bventy email address was changed",
+		Body: "The email address on your Bventy account was changed to " + maskEmail(newEmail) + ".\n\n" +
+			"If this wasn't you, contact support immediately.",
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email address updated. Sign in with your new email from now on."})
+}
+
+// maskEmail keeps enough of an address to recognise it: "jane.doe@x.com" -> "j*******@x.com"
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 1 {
+		return email
+	}
+	return email[:1] + strings.Repeat("*", at-1) + email[at:]
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/mailer"
+	"github.com/gin-gonic/gin"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// GET /admin/vendors/kyc-queue
+// Vendors whose documents await review, oldest submission first
+func (h *AdminHandler) GetKYCQueue(c *gin.Context) {
+	rows, err := db.Pool.Query(c.Request.Context(), `
+		SELECT vp.id, vp.business_name, vp.category, vp.city, vp.kyc_submitted_at,
+		       (SELECT COUNT(*) FROM vendor_kyc_reviews r WHERE r.vendor_id = vp.id AND r.action = 'rejected')
+		FROM vendor_profiles vp
+		WHERE vp.status = 'pending' AND vp.kyc_submitted_at IS NOT NULL
+		ORDER BY vp.kyc_submitted_at
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review queue"})
+		return
+	}
+	defer rows.Close()
+
+	vendors := []gin.H{}
+	for rows.Next() {
+		var id, businessName, category, city string
+		var submittedAt time.Time
+		var rejections int
+		if err := rows.Scan(&id, &businessName, &category, &city, &submittedAt, &rejections); err != nil {
+			continue
+		}
+		vendors = append(vendors, gin.H{
+			"id":                  id,
+			"business_name":       businessName,
+			"category":            category,
+			"city":                city,
+			"submitted_at":        submittedAt,
+			"previous_rejections": rejections,
+		})
+	}
+
+	c.JSON(http.StatusOK, vendors)
+}
+
+// GET /admin/vendors/:id/kyc
+// Everything a reviewer needs: the vendor, all documents ever uploaded (with
+// signed links) and the full decision history
+func (h *AdminHandler) GetVendorKYC(c *gin.Context) {
+	vendorID := c.Param("id")
+	ctx := c.Request.Context()
+
+	var businessName, category, city, status, ownerName, ownerEmail string
+	var submittedAt, reviewedAt *time.Time
+	var rejectionReason *string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT vp.business_name, vp.category, vp.city, vp.status, vp.kyc_submitted_at, vp.reviewed_at, vp.rejection_reason,
+		       u.full_name, u.email
+		FROM vendor_profiles vp JOIN users u ON u.id = vp.owner_user_id
+		WHERE vp.id = $1
+	`, vendorID).Scan(&businessName, &category, &city, &status, &submittedAt, &reviewedAt, &rejectionReason, &ownerName, &ownerEmail)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	documents, err := loadKYCDocuments(ctx, vendorID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch documents"})
+		return
+	}
+	signKYCDocuments(h.MediaService, h.Config.PrivateFileURLTTL, documents)
+
+	history, err := loadKYCHistory(ctx, vendorID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vendor": gin.H{
+			"id":            vendorID,
+			"business_name": businessName,
+			"category":      category,
+			"city":          city,
+			"owner_name":    ownerName,
+			"owner_email":   ownerEmail,
+		},
+		"status":           status,
+		"submitted_at":     submittedAt,
+		"reviewed_at":      reviewedAt,
+		"rejection_reason": rejectionReason,
+		"documents":        documents,
+		"history":          history,
+	})
+}
+
+// PATCH /admin/vendors/:id/approve
+func (h *AdminHandler) VerifyVendor(c *gin.Context) {
+	h.decideKYC(c, "approved", "")
+}
+
+// PATCH /admin/vendors/:id/reject {"reason": "..."}
+// The reason is shown to the vendor, who can fix their documents and resubmit
+func (h *AdminHandler) RejectVendor(c *gin.Context) {
+	reason, ok := bindKYCReason(c, "rejection")
+	if !ok {
+		return
+	}
+	h.decideKYC(c, "rejected", reason)
+}
+
+// PATCH /admin/vendors/:id/revoke {"reason": "..."}
+// Withdraws a verification, e.g. when a document turns out to be invalid. The
+// vendor leaves the directory and can resubmit like after a rejection.
+func (h *AdminHandler) RevokeVendorVerification(c *gin.Context) {
+	reason, ok := bindKYCReason(c, "revocation")
+	if !ok {
+		return
+	}
+	h.decideKYC(c, "revoked", reason)
+}
+
+func bindKYCReason(c *gin.Context, kind string) (string, bool) {
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&input)
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" || len(reason) > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A " + kind + " reason of up to 1000 characters is required"})
+		return "", false
+	}
+	return reason, true
+}
+
+// decideKYC records a decision on a pending vendor, or a revocation of a
+// verified one: it updates the profile, appends to the review history and lets
+// the owner know. Vendors left pending from before KYC never submitted
+// documents; they can still be decided on, with an empty document list.
+func (h *AdminHandler) decideKYC(c *gin.Context, action, reason string) {
+	vendorID := c.Param("id")
+	actorID := c.MustGet("userID").(string)
+	ctx := c.Request.Context()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record decision"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	fromStatus, notFound := "pending", "Vendor not found or not awaiting review"
+	if action == "revoked" {
+		fromStatus, notFound = "verified", "Vendor not found or not verified"
+	}
+
+	var businessName, ownerEmail string
+	var documentIDs []string
+	err = tx.QueryRow(ctx, `
+		SELECT vp.business_name, u.email,
+		       (SELECT COALESCE(array_agg(d.id::text ORDER BY d.created_at), '{}') FROM vendor_kyc_documents d
+		        WHERE d.vendor_id = vp.id AND d.removed_at IS NULL)
+		FROM vendor_profiles vp JOIN users u ON u.id = vp.owner_user_id
+		WHERE vp.id = $1 AND vp.status = $2
+		FOR UPDATE OF vp
+	`, vendorID, fromStatus).Scan(&businessName, &ownerEmail, &documentIDs)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record decision"})
+		return
+	}
+
+	status := "rejected"
+	if action == "approved" {
+		status = "verified"
+	}
+	var nullableReason *string
+	if reason != "" {
+		nullableReason = &reason
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE vendor_profiles
+		SET status = $2, rejection_reason = $3, kyc_submitted_at = NULL, reviewed_at = NOW(), reviewed_by = $4, updated_at = NOW()
+		WHERE id = $1
+	`, vendorID, status, nullableReason, actorID)
+	if err == nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO vendor_kyc_reviews (vendor_id, action, reason, document_ids, actor_user_id) VALUES ($1, $2, $3, $4::uuid[], $5)
+		`, vendorID, action, nullableReason, documentIDs, actorID)
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to record KYC decision: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record decision"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record decision"})
+		return
+	}
+
+	metadata := map[string]interface{}{"document_ids": documentIDs}
+	if reason != "" {
+		metadata["reason"] = reason
+	}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('vendor', $1, $2, $3, $4)`, vendorID, "kyc_"+action, actorID, metadata)
+
+	msg := mailer.Message{
+		To:      ownerEmail,
+		Subject: businessName + " is now verified on Bventy",
+		Body:    "Your verification documents were approved and " + businessName + " is now listed in the Bventy vendor directory.",
+	}
+	switch action {
+	case "rejected":
+		msg.Subject = "Action needed: verification of " + businessName
+		msg.Body = "We couldn't verify " + businessName + " from the documents you submitted:\n\n" + reason + "\n\n" +
+			"You can upload updated documents and resubmit from your vendor dashboard:\n" + h.Config.FrontendURL + "/vendor/verification"
+	case "revoked":
+		msg.Subject = "Verification of " + businessName + " was withdrawn"
+		msg.Body = "We have withdrawn the verification of " + businessName + " and removed it from the vendor directory:\n\n" + reason + "\n\n" +
+			"You can upload updated documents and resubmit from your vendor dashboard:\n" + h.Config.FrontendURL + "/vendor/verification"
+	}
+	mailer.SendAsync(h.Mailer, msg)
+
+	switch action {
+	case "rejected":
+		c.JSON(http.StatusOK, gin.H{"message": "Vendor rejected successfully"})
+	case "revoked":
+		c.JSON(http.StatusOK, gin.H{"message": "Vendor verification revoked successfully"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Vendor verified successfully"})
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// GET /admin/permissions
+// Lists every permission with the roles that hold it by default
+func (h *AdminHandler) ListPermissions(c *gin.Context) {
+	query := `
+		SELECT p.code, COALESCE(p.description, ''), COALESCE(array_agg(rp.role ORDER BY rp.role) FILTER (WHERE rp.role IS NOT NULL), '{}')
+		FROM permissions p
+		LEFT JOIN role_permissions rp ON rp.permission_id = p.id
+		GROUP BY p.id, p.code, p.description
+		ORDER BY p.code
+	`
+	rows, err := db.Pool.Query(context.Background(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch permissions"})
+		return
+	}
+	defer rows.Close()
+
+	var permissions []gin.H
+	for rows.Next() {
+		var code, description string
+		var roles []string
+		if err := rows.Scan(&code, &description, &roles); err != nil {
+			continue
+		}
+		permissions = append(permissions, gin.H{
+			"code":          code,
+			"description":   description,
+			"default_roles": roles,
+		})
+	}
+
+	if permissions == nil {
+		permissions = []gin.H{}
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// GET /admin/users/:id/permissions
+// Effective permissions for a user, split by where they come from
+func (h *AdminHandler) GetUserPermissions(c *gin.Context) {
+	targetUserID := c.Param("id")
+	ctx := context.Background()
+
+	var role string
+	if err := db.Pool.QueryRow(ctx, "SELECT role FROM users WHERE id = $1", targetUserID).Scan(&role); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	query := `
+		SELECT p.code,
+		       EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.permission_id = p.id AND rp.role = $2),
+		       EXISTS (SELECT 1 FROM user_permissions up WHERE up.permission_id = p.id AND up.user_id = $1)
+		FROM permissions p
+		ORDER BY p.code
+	`
+	rows, err := db.Pool.Query(ctx, query, targetUserID, role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch permissions"})
+		return
+	}
+	defer rows.Close()
+
+	fromRole := []string{}
+	granted := []string{}
+	for rows.Next() {
+		var code string
+		var viaRole, viaGrant bool
+		if err := rows.Scan(&code, &viaRole, &viaGrant); err != nil {
+			continue
+		}
+		if viaRole || role == "super_admin" {
+			fromRole = append(fromRole, code)
+		}
+		if viaGrant {
+			granted = append(granted, code)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":   targetUserID,
+		"role":      role,
+		"from_role": fromRole,
+		"granted":   granted,
+	})
+}
+
+type GrantPermissionRequest struct {
+	Permission string `json:"permission" binding:"required"`
+}
+
+// POST /admin/users/:id/permissions
+func (h *AdminHandler) GrantPermission(c *gin.Context) {
+	targetUserID := c.Param("id")
+	actorID := c.MustGet("userID").(string)
+	actorRole := c.MustGet("role").(string)
+
+	var req GrantPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	// Prevent escalation: you can only hand out what you already hold, and
+	// only to users below your own role
+	if !middleware.HasPermission(ctx, actorID, actorRole, req.Permission) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot grant a permission you do not hold"})
+		return
+	}
+	if !canManageUser(c, targetUserID, "grant permissions to") {
+		return
+	}
+
+	query := `
+		INSERT INTO user_permissions (user_id, permission_id, granted_by)
+		SELECT $1, p.id, $3 FROM permissions p WHERE p.code = $2
+		ON CONFLICT (user_id, permission_id) DO NOTHING
+	`
+	tag, err := db.Pool.Exec(ctx, query, targetUserID, req.Permission, actorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		var known bool
+		_ = db.Pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM permissions WHERE code = $1)", req.Permission).Scan(&known)
+		if !known {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown permission"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Permission already granted"})
+		return
+	}
+
+	metadata := map[string]interface{}{"permission": req.Permission}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'permission_granted', $2, $3)`, targetUserID, actorID, metadata)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permission granted successfully"})
+}
+
+// DELETE /admin/users/:id/permissions/:code
+// Removes a direct grant; role defaults are unaffected
+func (h *AdminHandler) RevokePermission(c *gin.Context) {
+	targetUserID := c.Param("id")
+	code := c.Param("code")
+	actorID := c.MustGet("userID").(string)
+	actorRole := c.MustGet("role").(string)
+
+	ctx := context.Background()
+
+	// Same rules as granting, so nobody can strip access they could not give
+	if !middleware.HasPermission(ctx, actorID, actorRole, code) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot revoke a permission you do not hold"})
+		return
+	}
+	if !canManageUser(c, targetUserID, "revoke permissions from") {
+		return
+	}
+
+	query := `
+		DELETE FROM user_permissions
+		WHERE user_id = $1 AND permission_id = (SELECT id FROM permissions WHERE code = $2)
+	`
+	tag, err := db.Pool.Exec(ctx, query, targetUserID, code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke permission"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User does not have this permission granted"})
+		return
+	}
+
+	metadata := map[string]interface{}{"permission": code}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'permission_revoked', $2, $3)`, targetUserID, actorID, metadata)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permission revoked successfully"})
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// GET /admin/reviews/reported
+// Reviews with unresolved reports, most reported first
+func (h *AdminHandler) ListReportedReviews(c *gin.Context) {
+	query := `
+		SELECT r.id, r.vendor_id, vp.business_name, r.rating, r.body, r.vendor_reply, r.status, r.created_at,
+		       COALESCE(u.full_name, 'Deleted user'),
+		       COUNT(rr.id), array_agg(rr.reason ORDER BY rr.created_at), MAX(rr.created_at)
+		FROM vendor_reviews r
+		JOIN vendor_review_reports rr ON rr.review_id = r.id AND rr.resolved_at IS NULL
+		JOIN vendor_profiles vp ON vp.id = r.vendor_id
+		LEFT JOIN users u ON u.id = r.reviewer_user_id
+		GROUP BY r.id, vp.business_name, u.full_name
+		ORDER BY COUNT(rr.id) DESC, MAX(rr.created_at) DESC
+	`
+	rows, err := db.Pool.Query(context.Background(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reported reviews"})
+		return
+	}
+	defer rows.Close()
+
+	var reviews []gin.H
+	for rows.Next() {
+		var id, vendorID, businessName, body, status, reviewerName string
+		var rating, reportCount int
+		var reply *string
+		var reasons []string
+		var createdAt, lastReportedAt time.Time
+		if err := rows.Scan(&id, &vendorID, &businessName, &rating, &body, &reply, &status, &createdAt,
+			&reviewerName, &reportCount, &reasons, &lastReportedAt); err != nil {
+			continue
+		}
+		reviews = append(reviews, gin.H{
+			"id":               id,
+			"vendor_id":        vendorID,
+			"business_name":    businessName,
+			"rating":           rating,
+			"body":             body,
+			"vendor_reply":     reply,
+			"status":           status,
+			"created_at":       createdAt,
+			"reviewer_name":    reviewerName,
+			"report_count":     reportCount,
+			"report_reasons":   reasons,
+			"last_reported_at": lastReportedAt,
+		})
+	}
+
+	if reviews == nil {
+		reviews = []gin.H{}
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+// PATCH /admin/reviews/:id/hide
+// Removes a review from the vendor's profile and rating. Open reports are resolved.
+func (h *AdminHandler) HideReview(c *gin.Context) {
+	var input struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil || strings.TrimSpace(input.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+
+	h.moderateReview(c, "hidden", strings.TrimSpace(input.Reason))
+}
+
+// PATCH /admin/reviews/:id/restore
+// Publishes a previously hidden review again
+func (h *AdminHandler) RestoreReview(c *gin.Context) {
+	h.moderateReview(c, "published", "")
+}
+
+// PATCH /admin/reviews/:id/dismiss-reports
+// Keeps the review published and closes its open reports
+func (h *AdminHandler) DismissReviewReports(c *gin.Context) {
+	adminID := c.MustGet("userID").(string)
+	reviewID := c.Param("id")
+	ctx := context.Background()
+
+	tag, err := db.Pool.Exec(ctx, `
+		UPDATE vendor_review_reports SET resolved_at = NOW(), resolved_by = $2, resolution = 'dismissed'
+		WHERE review_id = $1 AND resolved_at IS NULL
+	`, reviewID, adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dismiss reports"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No open reports for this review"})
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('review', $1, 'review_reports_dismissed', $2)`, reviewID, adminID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reports dismissed"})
+}
+
+func (h *AdminHandler) moderateReview(c *gin.Context, status, reason string) {
+	adminID := c.MustGet("userID").(string)
+	reviewID := c.Param("id")
+	ctx := context.Background()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var hiddenReason *string
+	if reason != "" {
+		hiddenReason = &reason
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE vendor_reviews SET status = $2, hidden_reason = $3, moderated_by = $4, moderated_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status != $2
+	`, reviewID, status, hiddenReason, adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found or already " + status})
+		return
+	}
+
+	if status == "hidden" {
+		_, err = tx.Exec(ctx, `
+			UPDATE vendor_review_reports SET resolved_at = NOW(), resolved_by = $2, resolution = 'hidden'
+			WHERE review_id = $1 AND resolved_at IS NULL
+		`, reviewID, adminID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review"})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review"})
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('review', $1, $2, $3)`,
+		reviewID, "review_"+status, adminID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Review " + status})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/bventy/backend/internal/auth"
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+const maxAPIKeysPerUser = 10
+
+type APIKeyHandler struct{}
+
+func NewAPIKeyHandler() *APIKeyHandler {
+	return &APIKeyHandler{}
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP *string    `json:"last_used_ip"`
+}
+
+// GET /me/api-keys
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	query := `
+		SELECT id, name, prefix, scopes, created_at, expires_at, last_used_at, last_used_ip
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+	rows, err := db.Pool.Query(context.Background(), query, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+	defer rows.Close()
+
+	keys := []APIKeyResponse{}
+	for rows.Next() {
+		var k APIKeyResponse
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt, &k.LastUsedIP); err != nil {
+			continue
+		}
+		keys = append(keys, k)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keys": keys, "available_scopes": auth.APIKeyScopes})
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0,max=730"`
+}
+
+// POST /me/api-keys
+// The full key is only ever returned here.
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scopes := []string{}
+	seen := map[string]bool{}
+	for _, scope := range req.Scopes {
+		if !auth.IsValidAPIKeyScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope})
+			return
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	ctx := context.Background()
+
+	var count int
+	_ = db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL`, userID).Scan(&count)
+	if count >= maxAPIKeysPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "API key limit reached, revoke an unused key first"})
+		return
+	}
+
+	key, prefix, keyHash, err := auth.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	var k APIKeyResponse
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, name, prefix, scopes, created_at, expires_at
+	`
+	err = db.Pool.QueryRow(ctx, query, userID, req.Name, prefix, keyHash, scopes, expiresAt).Scan(
+		&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.CreatedAt, &k.ExpiresAt,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	metadata := map[string]interface{}{"prefix": prefix, "scopes": scopes}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'api_key_created', $1, $2)`, userID, metadata)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"key":     key,
+		"api_key": k,
+		"message": "Store this key now, it will not be shown again",
+	})
+}
+
+// DELETE /me/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	keyID := c.Param("id")
+
+	query := `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id::text = $1 AND user_id = $2 AND revoked_at IS NULL
+		RETURNING prefix
+	`
+	var prefix string
+	if err := db.Pool.QueryRow(context.Background(), query, keyID, userID).Scan(&prefix); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	metadata := map[string]interface{}{"prefix": prefix}
+	_, _ = db.Pool.Exec(context.Background(), `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'api_key_revoked', $1, $2)`, userID, metadata)
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
@@ -2,21 +2,28 @@ package handlers
 
 import (
 	"context"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/bventy/backend/internal/auth"
 	"github.com/bventy/backend/internal/config"
 	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/mailer"
+	pgx "github.com/jackc/pgx/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthHandler struct {
 	Config *config.Config
+	Mailer mailer.Mailer
 }
 
 func NewAuthHandler(cfg *config.Config) *AuthHandler {
-	return &AuthHandler{Config: cfg}
+	return &AuthHandler{Config: cfg, Mailer: mailer.New(cfg)}
 }
 
 type SignupRequest struct {
@@ -66,20 +73,27 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		return
 	}
 
-	token, err := auth.GenerateToken(userID, "user", h.Config)
+	if err := h.sendVerificationEmail(context.Background(), userID, req.Email); err != nil {
+		log.Printf("ERROR: Failed to issue verification email: %v", err)
+	}
+
+	tokens, err := auth.CreateSession(c.Request.Context(), userID, c.Request.UserAgent(), c.ClientIP(), h.Config)
 	if err != nil {
 		c.JSON(http.StatusCreated, gin.H{"message": "User created, please login", "user_id": userID})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "User created successfully",
-		"token":   token,
+		"message":       "User created successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 		"user": gin.H{
-			"id":        userID,
-			"email":     req.Email,
-			"full_name": req.FullName,
-			"role":      "user",
+			"id":             userID,
+			"email":          req.Email,
+			"full_name":      req.FullName,
+			"role":           "user",
+			"email_verified": false,
 		},
 	})
 }
@@ -96,30 +110,421 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	ctx := context.Background()
+
+	throttle := auth.CheckLoginAllowed(ctx, req.Email, c.ClientIP())
+	if !throttle.Allowed() {
+		respondLoginThrottled(c, throttle)
+		return
+	}
+
 	var userID, role, passwordHash, fullName string
-	query := `SELECT id, role, password_hash, full_name FROM users WHERE email = $1`
-	err := db.Pool.QueryRow(context.Background(), query, req.Email).Scan(&userID, &role, &passwordHash, &fullName)
+	var twoFactorEnabled bool
+	query := `SELECT id, role, password_hash, full_name, totp_enabled_at IS NOT NULL FROM users WHERE email = $1`
+	err := db.Pool.QueryRow(ctx, query, req.Email).Scan(&userID, &role, &passwordHash, &fullName, &twoFactorEnabled)
 	if err != nil {
+		h.recordLoginFailure(c, req.Email, "")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password))
 	if err != nil {
+		h.recordLoginFailure(c, req.Email, userID)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	token, err := auth.GenerateToken(userID, role, h.Config)
+	// Password is correct but a second factor is still required
+	if twoFactorEnabled {
+		challenge, err := auth.GenerateChallengeToken(userID, h.Config)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+		})
+		return
+	}
+
+	// Counter is only cleared once login fully succeeds, so 2FA guesses keep counting
+	auth.ResetLoginFailures(ctx, req.Email)
+	h.issueSession(c, userID, role, fullName)
+}
+
+// recordLoginFailure feeds the throttle and records lockouts in the activity log.
+// userID is empty when the email does not belong to an account.
+func (h *AuthHandler) recordLoginFailure(c *gin.Context, email, userID string) {
+	ctx := context.Background()
+	ip := c.ClientIP()
+
+	accountLocked, ipLocked := auth.RecordLoginFailure(ctx, email, ip, h.Config)
+	if accountLocked && userID != "" {
+		metadata := map[string]interface{}{"ip": ip, "duration": h.Config.LoginLockoutDuration.String()}
+		_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'account_locked', NULL, $2)`, userID, metadata)
+	}
+	if ipLocked {
+		log.Printf("⚠️  Login lockout for IP %s after repeated failures", ip)
+	}
+}
+
+func respondLoginThrottled(c *gin.Context, throttle auth.LoginThrottleResult) {
+	retryAfter := int(throttle.RetryAfter.Seconds()) + 1
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	message := "Too many failed attempts, please wait before trying again"
+	if throttle.Locked {
+		message = "Account temporarily locked due to repeated failed logins"
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": message, "retry_after": retryAfter})
+}
+
+// issueSession starts a session and writes the standard login response
+func (h *AuthHandler) issueSession(c *gin.Context, userID, role, fullName string) {
+	tokens, err := auth.CreateSession(c.Request.Context(), userID, c.Request.UserAgent(), c.ClientIP(), h.Config)
+	if err == auth.ErrAccountSuspended {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token":     token,
-		"role":      role,
-		"user_id":   userID,
-		"full_name": fullName,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"role":          role,
+		"user_id":       userID,
+		"full_name":     fullName,
 	})
 }
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// POST /auth/refresh
+// Exchanges a refresh token for a new access token and a rotated refresh token
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := auth.RefreshSession(c.Request.Context(), req.RefreshToken, h.Config)
+	if err != nil {
+		switch err {
+		case auth.ErrInvalidRefreshToken, auth.ErrRefreshTokenReused, auth.ErrSessionRevoked, auth.ErrAccountSuspended:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// POST /auth/logout
+// Revokes the session behind the refresh token; its access tokens stop working immediately
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := auth.RevokeSessionByRefreshToken(c.Request.Context(), req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// POST /auth/forgot-password
+// Always answers the same way so the endpoint cannot be used to probe for accounts
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "If an account exists for this email, a reset link has been sent"}
+	ctx := context.Background()
+
+	var userID, email string
+	err := db.Pool.QueryRow(ctx, `SELECT id, email FROM users WHERE email = $1`, req.Email).Scan(&userID, &email)
+	if err == nil {
+		// Issue the link in the background: the lookup above is the only work
+		// both cases share, so the response time does not reveal the account
+		go h.issuePasswordReset(userID, email, c.ClientIP())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// issuePasswordReset replaces any outstanding reset link for the user with a
+// new one and emails it.
+func (h *AuthHandler) issuePasswordReset(userID, email, requestedIP string) {
+	ctx := context.Background()
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("ERROR: Failed to generate reset token: %v", err)
+		return
+	}
+
+	// Only the latest link should work
+	_, _ = db.Pool.Exec(ctx, `UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`, userID)
+
+	insertQuery := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, requested_ip, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err = db.Pool.Exec(ctx, insertQuery, userID, tokenHash, requestedIP, time.Now().Add(h.Config.PasswordResetTTL))
+	if err != nil {
+		log.Printf("ERROR: Failed to create reset token: %v", err)
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'password_reset_requested', $1)`, userID)
+
+	link := h.Config.FrontendURL + "/reset-password?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      email,
+		Subject: "Reset your Bventy password",
+		Body: "We received a request to reset your Bventy password.\n\n" +
+			"Open this link to choose a new one (valid for " + h.Config.PasswordResetTTL.String() + "):\n" + link + "\n\n" +
+			"If you did not request this, you can ignore this email.",
+	}
+	mailer.SendAsync(h.Mailer, msg)
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// POST /auth/reset-password
+// Consumes a reset token, sets the new password and signs the user out everywhere
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	ctx := context.Background()
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var tokenID, userID string
+	tokenQuery := `
+		SELECT id, user_id FROM password_reset_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`
+	err = tx.QueryRow(ctx, tokenQuery, auth.HashToken(req.Token)).Scan(&tokenID, &userID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reset link is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate reset token"})
+		return
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE password_reset_tokens SET used_at = NOW() WHERE id = $1`, tokenID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to consume reset token"})
+		return
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE users SET password_hash = $1, token_version = token_version + 1, updated_at = NOW() WHERE id = $2`, string(hashedPassword), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	if err := auth.RevokeUserSessions(ctx, userID, "", "password_reset"); err != nil {
+		log.Printf("ERROR: Failed to revoke sessions after password reset: %v", err)
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'password_reset_completed', $1)`, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset. Please login with your new password."})
+}
+
+// sendVerificationEmail issues a fresh verification token for the address and mails the link
+func (h *AuthHandler) sendVerificationEmail(ctx context.Context, userID, email string) error {
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	insertQuery := `
+		INSERT INTO email_verification_tokens (user_id, email, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err = db.Pool.Exec(ctx, insertQuery, userID, email, tokenHash, time.Now().Add(h.Config.EmailVerificationTTL))
+	if err != nil {
+		return err
+	}
+
+	link := h.Config.FrontendURL + "/verify-email?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      email,
+		Subject: "Verify your Bventy email address",
+		Body: "Welcome to Bventy!\n\n" +
+			"Please confirm your email address by opening this link:\n" + link + "\n\n" +
+			"If you did not create an account, you can ignore this email.",
+	}
+	mailer.SendAsync(h.Mailer, msg)
+
+	return nil
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// POST /auth/verify-email
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var tokenID, userID, email string
+	tokenQuery := `
+		SELECT id, user_id, email FROM email_verification_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`
+	err = tx.QueryRow(ctx, tokenQuery, auth.HashToken(req.Token)).Scan(&tokenID, &userID, &email)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification link is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate verification token"})
+		return
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE email_verification_tokens SET used_at = NOW() WHERE id = $1`, tokenID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to consume verification token"})
+		return
+	}
+
+	// Only verify if the account still uses the address the link was sent to
+	updateQuery := `
+		UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW())
+		WHERE id = $1 AND email = $2
+		RETURNING id
+	`
+	var id string
+	err = tx.QueryRow(ctx, updateQuery, userID, email).Scan(&id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification link no longer matches your account email"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'email_verified', $1)`, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// POST /auth/resend-verification (Protected)
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	ctx := context.Background()
+
+	var email string
+	var verifiedAt *time.Time
+	err := db.Pool.QueryRow(ctx, `SELECT email, email_verified_at FROM users WHERE id = $1`, userID).Scan(&email, &verifiedAt)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if verifiedAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	// Cooldown: measured in the database to avoid clock/timezone skew
+	var secondsSinceLast *float64
+	_ = db.Pool.QueryRow(ctx, `SELECT EXTRACT(EPOCH FROM (NOW() - MAX(created_at)))::float8 FROM email_verification_tokens WHERE user_id = $1`, userID).Scan(&secondsSinceLast)
+	if secondsSinceLast != nil {
+		elapsed := time.Duration(*secondsSinceLast * float64(time.Second))
+		if remaining := h.Config.VerificationResendCooldown - elapsed; remaining > 0 {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Please wait before requesting another verification email",
+				"retry_after": int(remaining.Seconds()) + 1,
+			})
+			return
+		}
+	}
+
+	if err := h.sendVerificationEmail(ctx, userID, email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+// GET /.well-known/jwks.json
+// Publishes the public keys other services use to verify our access tokens
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	jwks, err := auth.JWKS(h.Config)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load signing keys"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	dateLayout              = "2006-01-02"
+	defaultAvailabilityDays = 90
+	maxAvailabilityDays     = 366
+)
+
+var availabilityStatuses = map[string]bool{"booked": true, "blocked": true, "tentative": true}
+
+type AvailabilityEntry struct {
+	Date    string  `json:"date"`
+	Status  string  `json:"status"`
+	Note    *string `json:"note,omitempty"`
+	QuoteID *string `json:"quote_id,omitempty"`
+}
+
+type UpdateAvailabilityRequest struct {
+	Dates  []string `json:"dates" binding:"required"`
+	Status string   `json:"status" binding:"required"` // booked, blocked, tentative or available (clears the date)
+	Note   *string  `json:"note"`
+}
+
+// GET /vendor/me/availability?from=&to=
+func (h *VendorHandler) GetMyAvailability(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	from, to, ok := availabilityRange(c)
+	if !ok {
+		return
+	}
+
+	vendorID, _, ok := requireVendorRole(c, vendorRoleResponder)
+	if !ok {
+		return
+	}
+
+	entries, err := loadAvailability(ctx, vendorID, from, to, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch availability"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":         from.Format(dateLayout),
+		"to":           to.Format(dateLayout),
+		"availability": entries,
+	})
+}
+
+// PUT /vendor/me/availability
+// Marks dates booked, blocked or tentative, or "available" to clear them.
+// The vendor team has the last word: this also overrides dates booked through
+// an accepted quote, dropping the link to the quote.
+func (h *VendorHandler) UpdateMyAvailability(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req UpdateAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Status != "available" && !availabilityStatuses[req.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be booked, blocked, tentative or available"})
+		return
+	}
+	if len(req.Dates) == 0 || len(req.Dates) > maxAvailabilityDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide between 1 and 366 dates"})
+		return
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	dates := make([]time.Time, 0, len(req.Dates))
+	for _, raw := range req.Dates {
+		d, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date " + raw + ", expected YYYY-MM-DD"})
+			return
+		}
+		if d.Before(today) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change availability for past date " + raw})
+			return
+		}
+		dates = append(dates, d)
+	}
+
+	vendorID, _, ok := requireVendorRole(c, vendorRoleManager)
+	if !ok {
+		return
+	}
+
+	var query string
+	args := []interface{}{vendorID, dates}
+	if req.Status == "available" {
+		query = `
+			DELETE FROM vendor_availability
+			WHERE vendor_id = $1 AND date = ANY($2::date[])
+		`
+	} else {
+		query = `
+			INSERT INTO vendor_availability (vendor_id, date, status, note)
+			SELECT $1, d, $3, $4 FROM unnest($2::date[]) AS d
+			ON CONFLICT (vendor_id, date) DO UPDATE
+			SET status = EXCLUDED.status, note = EXCLUDED.note, quote_id = NULL, updated_at = NOW()
+		`
+		args = append(args, req.Status, req.Note)
+	}
+
+	tag, err := db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("ERROR: Failed to update availability: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update availability"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Availability updated",
+		"updated": tag.RowsAffected(),
+	})
+}
+
+// GET /vendors/slug/:slug/availability?from=&to=
+// Public view of the calendar: dates and their status only
+func (h *VendorHandler) GetVendorAvailability(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	from, to, ok := availabilityRange(c)
+	if !ok {
+		return
+	}
+
+	vendorID, _, err := resolveVendorSlug(ctx, c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	entries, err := loadAvailability(ctx, vendorID, from, to, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch availability"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":         from.Format(dateLayout),
+		"to":           to.Format(dateLayout),
+		"availability": entries,
+	})
+}
+
+// availabilityRange reads ?from=&to= (YYYY-MM-DD), defaulting to the next 90
+// days. It writes the error response itself when the range is invalid.
+func availabilityRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from := time.Now().Truncate(24 * time.Hour)
+	if raw := c.Query("from"); raw != "" {
+		d, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date (YYYY-MM-DD)"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = d
+	}
+	to := from.AddDate(0, 0, defaultAvailabilityDays)
+	if raw := c.Query("to"); raw != "" {
+		d, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date (YYYY-MM-DD)"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = d
+	}
+	if to.Before(from) || to.Sub(from) > maxAvailabilityDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from and at most 366 days later"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// loadAvailability lists the unavailable dates in [from, to]. Notes and quote
+// links are private to the vendor.
+func loadAvailability(ctx context.Context, vendorID string, from, to time.Time, private bool) ([]AvailabilityEntry, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT date, status, note, quote_id::text FROM vendor_availability
+		WHERE vendor_id = $1 AND date BETWEEN $2 AND $3
+		ORDER BY date
+	`, vendorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AvailabilityEntry{}
+	for rows.Next() {
+		var date time.Time
+		var e AvailabilityEntry
+		if err := rows.Scan(&date, &e.Status, &e.Note, &e.QuoteID); err != nil {
+			return nil, err
+		}
+		e.Date = date.Format(dateLayout)
+		if !private {
+			e.Note, e.QuoteID = nil, nil
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// vendorAvailabilityForEvent returns the vendor's calendar status on the
+// event's date, or "" when the date is open or the event has no date.
+func vendorAvailabilityForEvent(ctx context.Context, vendorID, eventID string) (string, string, error) {
+	var status, date string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(va.status, ''), COALESCE(to_char(e.event_date, 'YYYY-MM-DD'), '')
+		FROM events e
+		LEFT JOIN vendor_availability va ON va.vendor_id = $1 AND va.date = e.event_date::date
+		WHERE e.id = $2
+	`, vendorID, eventID).Scan(&status, &date)
+	return status, date, err
+}
+
+// bookVendorForQuote marks the event date of an accepted quote as booked.
+// Only quotes the vendor responded to book anything, so an organizer cannot
+// fill a calendar by accepting unanswered requests. An existing booking for
+// that date is kept.
+func bookVendorForQuote(ctx context.Context, quoteID string) error {
+	_, err := db.Pool.Exec(ctx, `
+		INSERT INTO vendor_availability (vendor_id, date, status, quote_id)
+		SELECT qr.vendor_id, e.event_date::date, 'booked', qr.id
+		FROM quote_requests qr JOIN events e ON e.id = qr.event_id
+		WHERE qr.id = $1 AND qr.responded_at IS NOT NULL AND e.event_date IS NOT NULL
+		ON CONFLICT (vendor_id, date) DO UPDATE
+		SET status = 'booked', quote_id = EXCLUDED.quote_id, updated_at = NOW()
+		WHERE vendor_availability.quote_id IS NULL
+	`, quoteID)
+	return err
+}
+
+// releaseVendorForQuote frees the date a quote booked once the booking no
+// longer stands. Past dates stay as a record of the job.
+func releaseVendorForQuote(ctx context.Context, quoteID string) error {
+	_, err := db.Pool.Exec(ctx, `
+		DELETE FROM vendor_availability WHERE quote_id = $1 AND date >= CURRENT_DATE
+	`, quoteID)
+	return err
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultSuggestionLimit = 10
+	maxSuggestionLimit     = 50
+)
+
+// GET /events/:id/vendor-suggestions?category=&radius_km=&limit=
+// Verified vendors serving the event's location: by distance when the event
+// has coordinates (within radius_km, default 30), otherwise by city. Vendors
+// already shortlisted or booked/blocked on the event date are left out.
+func (h *EventHandler) SuggestVendors(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	eventID := c.Param("id")
+	ctx := c.Request.Context()
+
+	limit := defaultSuggestionLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(n, maxSuggestionLimit)
+	}
+	radius, ok := geoRadius(c)
+	if !ok {
+		return
+	}
+
+	// Only the organizer, or members of the organizing group, get suggestions
+	var city string
+	var eventDate time.Time
+	var latitude, longitude *float64
+	var allowed bool
+	err := db.Pool.QueryRow(ctx, `
+		SELECT e.city, e.event_date, e.latitude, e.longitude,
+		       COALESCE(e.organizer_user_id = $2, false) OR EXISTS (
+		           SELECT 1 FROM group_members gm WHERE gm.group_id = e.organizer_group_id AND gm.user_id = $2)
+		FROM events e WHERE e.id = $1
+	`, eventID, userID).Scan(&city, &eventDate, &latitude, &longitude, &allowed)
+	if err == pgx.ErrNoRows || (err == nil && !allowed) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	args := []interface{}{eventID, eventDate}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	serves := servesCitySQL(addArg(city))
+	distance := "NULL::float8"
+	if latitude != nil && longitude != nil {
+		lat, lng := addArg(*latitude), addArg(*longitude)
+		box := areaBoxSQL(addArg, *latitude, *longitude, radius)
+		serves = "(" + servesPointSQL(lat, lng, addArg(radius), box) + " OR " + serves + ")"
+		distance = nearestAreaSQL(lat, lng)
+	}
+
+	where := []string{
+		"vp.status = 'verified'",
+		serves,
+		"NOT EXISTS (SELECT 1 FROM event_shortlisted_vendors esv WHERE esv.event_id = $1 AND esv.vendor_id = vp.id)",
+		"NOT EXISTS (SELECT 1 FROM vendor_availability va WHERE va.vendor_id = vp.id AND va.date = $2::date AND va.status IN ('booked', 'blocked'))",
+	}
+	if category := strings.TrimSpace(c.Query("category")); category != "" {
+		where = append(where, "lower(vp.category) = lower("+addArg(category)+")")
+	}
+
+	query := `
+		SELECT vp.id, vp.business_name, vp.slug, vp.category, vp.city, vp.portfolio_image_url,
+		       vp.rating_average::float8, vp.rating_count, ` + distance + ` AS distance
+		FROM vendor_profiles vp
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY distance NULLS LAST, vp.rating_average DESC NULLS LAST, vp.shortlist_count DESC, vp.id
+		LIMIT ` + addArg(limit)
+
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch suggestions"})
+		return
+	}
+	defer rows.Close()
+
+	vendors := []gin.H{}
+	for rows.Next() {
+		var id, name, slug, category, vendorCity string
+		var portfolioImageURL *string
+		var ratingAverage, distanceKm *float64
+		var ratingCount int
+		if err := rows.Scan(&id, &name, &slug, &category, &vendorCity, &portfolioImageURL, &ratingAverage, &ratingCount, &distanceKm); err != nil {
+			continue
+		}
+		vendors = append(vendors, gin.H{
+			"id":                  id,
+			"business_name":       name,
+			"slug":                slug,
+			"category":            category,
+			"city":                vendorCity,
+			"portfolio_image_url": portfolioImageURL,
+			"rating_average":      ratingAverage,
+			"rating_count":        ratingCount,
+			"distance_km":         roundKm(distanceKm),
+		})
+	}
+
+	location := gin.H{"city": city, "latitude": latitude, "longitude": longitude}
+	if latitude != nil {
+		location["radius_km"] = radius
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"event_id": eventID,
+		"location": location,
+		"vendors":  vendors,
+	})
+}
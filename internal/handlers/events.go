@@ -17,14 +17,16 @@ func NewEventHandler() *EventHandler {
 }
 
 type CreateEventRequest struct {
-	Title            string  `json:"title" binding:"required"`
-	City             string  `json:"city" binding:"required"`
-	EventType        string  `json:"event_type"`
-	Date             string  `json:"event_date" binding:"required"` // ISO string
-	BudgetMin        *int    `json:"budget_min"`
-	BudgetMax        *int    `json:"budget_max"`
-	OrganizerGroupID *string `json:"organizer_group_id"` // Optional
-	CoverImageURL    *string `json:"cover_image_url"`    // Optional
+	Title            string   `json:"title" binding:"required"`
+	City             string   `json:"city" binding:"required"`
+	EventType        string   `json:"event_type"`
+	Date             string   `json:"event_date" binding:"required"` // ISO string
+	BudgetMin        *int     `json:"budget_min"`
+	BudgetMax        *int     `json:"budget_max"`
+	OrganizerGroupID *string  `json:"organizer_group_id"` // Optional
+	CoverImageURL    *string  `json:"cover_image_url"`    // Optional
+	Latitude         *float64 `json:"latitude"`           // Optional, used for vendor suggestions
+	Longitude        *float64 `json:"longitude"`          // Optional
 }
 
 func (h *EventHandler) CreateEvent(c *gin.Context) {
@@ -50,6 +52,12 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		}
 	}
 
+	if (req.Latitude == nil) != (req.Longitude == nil) ||
+		(req.Latitude != nil && (*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "latitude and longitude must be given together as valid coordinates"})
+		return
+	}
+
 	var organizerUserID interface{} = userID
 	var organizerGroupID interface{} = nil
 
@@ -74,14 +82,15 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 
 	// Updated query to include cover_image_url and CORRECT column name event_date
 	query := `
-		INSERT INTO events (title, city, event_type, event_date, budget_min, budget_max, organizer_user_id, organizer_group_id, cover_image_url)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO events (title, city, event_type, event_date, budget_min, budget_max, organizer_user_id, organizer_group_id, cover_image_url, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
 	`
 
 	var eventID string
 	err = db.Pool.QueryRow(context.Background(), query,
 		req.Title, req.City, req.EventType, eventDate, req.BudgetMin, req.BudgetMax, organizerUserID, organizerGroupID, req.CoverImageURL,
+		req.Latitude, req.Longitude,
 	).Scan(&eventID)
 
 	if err != nil {
@@ -141,12 +150,18 @@ func (h *EventHandler) ListMyEvents(c *gin.Context) {
 }
 
 func (h *EventHandler) GetEventById(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
 	eventID := c.Param("id")
 
+	// The venue coordinates are often a home address: only the organizer and
+	// members of the organizing group see them
 	query := `
-		SELECT id, title, city, event_date, event_type, budget_min, budget_max, cover_image_url, organizer_user_id, organizer_group_id
-		FROM events
-		WHERE id = $1
+		SELECT e.id, e.title, e.city, e.event_date, e.event_type, e.budget_min, e.budget_max, e.cover_image_url,
+		       e.organizer_user_id, e.organizer_group_id, e.latitude, e.longitude,
+		       COALESCE(e.organizer_user_id = $2, false) OR EXISTS (
+		           SELECT 1 FROM group_members gm WHERE gm.group_id = e.organizer_group_id AND gm.user_id = $2)
+		FROM events e
+		WHERE e.id = $1
 	`
 
 	var event gin.H
@@ -154,9 +169,12 @@ func (h *EventHandler) GetEventById(c *gin.Context) {
 	var date time.Time
 	var budgetMin, budgetMax *int
 	var coverImageURL, organizerUserID, organizerGroupID *string
+	var latitude, longitude *float64
+	var isOrganizer bool
 
-	err := db.Pool.QueryRow(context.Background(), query, eventID).Scan(
+	err := db.Pool.QueryRow(context.Background(), query, eventID, userID).Scan(
 		&id, &title, &city, &date, &eventType, &budgetMin, &budgetMax, &coverImageURL, &organizerUserID, &organizerGroupID,
+		&latitude, &longitude, &isOrganizer,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -210,6 +228,10 @@ func (h *EventHandler) GetEventById(c *gin.Context) {
 		"organizer_group_id": organizerGroupID,
 		"shortlist":          shortlist,
 	}
+	if isOrganizer {
+		event["latitude"] = latitude
+		event["longitude"] = longitude
+	}
 
 	c.JSON(http.StatusOK, event)
 }
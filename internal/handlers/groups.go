@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/slug"
 )
 
 type GroupHandler struct{}
@@ -34,8 +36,6 @@ func (h *GroupHandler) CreateGroup(c *gin.Context) {
 		return
 	}
 
-	slug := generateSlug(req.Name, req.City)
-
 	// Transaction to create group AND add owner as member
 	tx, err := db.Pool.Begin(context.Background())
 	if err != nil {
@@ -50,9 +50,24 @@ func (h *GroupHandler) CreateGroup(c *gin.Context) {
 		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id
 	`
-	err = tx.QueryRow(context.Background(), queryGroup, req.Name, slug, req.City, req.Description, userID).Scan(&groupID)
+	// Each attempt runs in a savepoint so a slug collision doesn't abort the transaction
+	groupSlug, err := slug.Allocate(slug.Make(req.Name, req.City), "group", func(candidate string) error {
+		sp, err := tx.Begin(context.Background())
+		if err != nil {
+			return err
+		}
+		err = sp.QueryRow(context.Background(), queryGroup, req.Name, candidate, req.City, req.Description, userID).Scan(&groupID)
+		if err != nil {
+			sp.Rollback(context.Background())
+			if strings.Contains(err.Error(), "unique constraint") {
+				return slug.ErrTaken
+			}
+			return err
+		}
+		return sp.Commit(context.Background())
+	})
 	if err != nil {
-		if strings.Contains(err.Error(), "unique constraint") {
+		if errors.Is(err, slug.ErrTaken) {
 			c.JSON(http.StatusConflict, gin.H{"error": "Group name/slug unavailable"})
 			return
 		}
@@ -76,7 +91,7 @@ func (h *GroupHandler) CreateGroup(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Group created successfully", "group_id": groupID, "slug": slug})
+	c.JSON(http.StatusCreated, gin.H{"message": "Group created successfully", "group_id": groupID, "slug": groupSlug})
 }
 
 func (h *GroupHandler) ListMyGroups(c *gin.Context) {
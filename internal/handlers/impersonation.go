@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bventy/backend/internal/auth"
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// POST /superadmin/users/:id/impersonate
+// Mints a short-lived, read-only token that acts as the target user.
+func (h *AuthHandler) Impersonate(c *gin.Context) {
+	targetUserID := c.Param("id")
+	actorID := c.MustGet("userID").(string)
+
+	if _, ok := c.Get("impersonatorID"); ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot start impersonation from an impersonated session"})
+		return
+	}
+
+	var req ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason for impersonating is required"})
+		return
+	}
+
+	if targetUserID == actorID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot impersonate yourself"})
+		return
+	}
+
+	ctx := context.Background()
+	var email, fullName, role string
+	var suspended bool
+	err := db.Pool.QueryRow(ctx, `SELECT email, full_name, role, suspended_at IS NOT NULL FROM users WHERE id = $1`, targetUserID).Scan(&email, &fullName, &role, &suspended)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if role == "super_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Super admins cannot be impersonated"})
+		return
+	}
+	if suspended {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot impersonate a suspended account"})
+		return
+	}
+
+	pair, err := auth.CreateImpersonationSession(ctx, targetUserID, actorID, c.Request.UserAgent(), c.ClientIP(), h.Config)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start impersonation"})
+		return
+	}
+
+	metadata := map[string]interface{}{"reason": req.Reason, "session_id": pair.SessionID, "ip": c.ClientIP()}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'impersonation_started', $2, $3)`, targetUserID, actorID, metadata)
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":           pair.AccessToken,
+		"expires_in":      pair.ExpiresIn,
+		"impersonation":   true,
+		"impersonator_id": actorID,
+		"user": gin.H{
+			"id":        targetUserID,
+			"email":     email,
+			"full_name": fullName,
+			"role":      role,
+		},
+	})
+}
+
+// POST /auth/impersonation/stop (Protected, impersonation token)
+// Ends the impersonated session; the client falls back to its own token.
+func (h *AuthHandler) StopImpersonation(c *gin.Context) {
+	impersonatorID, ok := c.Get("impersonatorID")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not impersonating"})
+		return
+	}
+
+	sessionID := c.MustGet("sessionID").(string)
+	userID := c.MustGet("userID").(string)
+	ctx := context.Background()
+
+	if err := auth.RevokeSession(ctx, sessionID, "impersonation_ended"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end impersonation"})
+		return
+	}
+
+	metadata := map[string]interface{}{"session_id": sessionID}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'impersonation_ended', $2, $3)`, userID, impersonatorID, metadata)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Impersonation ended"})
+}
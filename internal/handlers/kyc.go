@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxKYCDocuments    = 10
+	maxKYCDocumentSize = 10 * 1024 * 1024
+)
+
+var kycDocumentTypes = map[string]bool{
+	"gst_certificate":       true,
+	"business_registration": true,
+	"id_proof":              true,
+	"address_proof":         true,
+	"other":                 true,
+}
+
+// Sniffed content types accepted for verification documents
+var kycContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+type KYCDocument struct {
+	ID           string     `json:"id"`
+	DocumentType string     `json:"document_type"`
+	FileName     string     `json:"file_name"`
+	ContentType  string     `json:"content_type"`
+	SizeBytes    int64      `json:"size_bytes"`
+	UploadedAt   time.Time  `json:"uploaded_at"`
+	RemovedAt    *time.Time `json:"removed_at,omitempty"`
+	URL          string     `json:"url,omitempty"` // short-lived signed link
+	storageKey   string
+}
+
+type KYCReview struct {
+	Action      string    `json:"action"` // submitted, approved or rejected
+	Reason      *string   `json:"reason"`
+	DocumentIDs []string  `json:"document_ids"`
+	ActorName   *string   `json:"actor_name,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GET /vendor/me/kyc
+// Verification status, the documents on file and every past submission and decision
+func (h *VendorHandler) GetMyKYC(c *gin.Context) {
+	vendorID, _, ok := requireVendorRole(c, vendorRoleOwner)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	var status string
+	var submittedAt, reviewedAt *time.Time
+	var rejectionReason *string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT status, kyc_submitted_at, reviewed_at, rejection_reason FROM vendor_profiles WHERE id = $1
+	`, vendorID).Scan(&status, &submittedAt, &reviewedAt, &rejectionReason)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor profile not found"})
+		return
+	}
+
+	documents, err := loadKYCDocuments(ctx, vendorID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch documents"})
+		return
+	}
+	signKYCDocuments(h.MediaService, h.Config.PrivateFileURLTTL, documents)
+
+	history, err := loadKYCHistory(ctx, vendorID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":           status,
+		"submitted_at":     submittedAt,
+		"reviewed_at":      reviewedAt,
+		"rejection_reason": rejectionReason,
+		"documents":        documents,
+		"history":          history,
+	})
+}
+
+// POST /vendor/me/kyc/documents (multipart: file, document_type)
+// PDF, JPEG or PNG up to 10MB, stored in private storage
+func (h *VendorHandler) UploadKYCDocument(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	documentType := c.PostForm("document_type")
+	if !kycDocumentTypes[documentType] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "document_type must be one of gst_certificate, business_registration, id_proof, address_proof, other"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
+		return
+	}
+	if fileHeader.Size > maxKYCDocumentSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File too large (max 10MB)"})
+		return
+	}
+
+	if h.MediaService == nil || h.MediaService.PrivateBucket == "" {
+		log.Printf("ERROR: KYC upload refused: R2_PRIVATE_BUCKET is not configured")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Document uploads are not available right now"})
+		return
+	}
+
+	vendorID, _, ok := requireVendorRole(c, vendorRoleOwner)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	if !kycEditable(c, vendorID) {
+		return
+	}
+
+	var count int
+	if err := db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM vendor_kyc_documents WHERE vendor_id = $1 AND removed_at IS NULL`, vendorID).Scan(&count); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if count >= maxKYCDocuments {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can keep at most 10 documents on file; remove one first"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+		return
+	}
+	defer file.Close()
+
+	// Trust the bytes, not the client's Content-Type header
+	head := make([]byte, 512)
+	n, _ := file.Read(head)
+	contentType := http.DetectContentType(head[:n])
+	if !kycContentTypes[contentType] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only PDF, JPEG and PNG files are allowed"})
+		return
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+
+	key, err := h.MediaService.UploadPrivateFile(file, fileHeader.Filename, contentType, fmt.Sprintf("kyc/%s", vendorID))
+	if err != nil {
+		log.Printf("ERROR: Failed to upload KYC document: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload document"})
+		return
+	}
+
+	var id string
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO vendor_kyc_documents (vendor_id, document_type, storage_key, file_name, content_type, size_bytes, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, vendorID, documentType, key, fileHeader.Filename, contentType, fileHeader.Size, userID).Scan(&id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save document metadata"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Document uploaded", "id": id})
+}
+
+// DELETE /vendor/me/kyc/documents/:id
+// The file is kept for the review history but no longer part of the next submission
+func (h *VendorHandler) RemoveKYCDocument(c *gin.Context) {
+	vendorID, _, ok := requireVendorRole(c, vendorRoleOwner)
+	if !ok {
+		return
+	}
+	if !kycEditable(c, vendorID) {
+		return
+	}
+
+	tag, err := db.Pool.Exec(c.Request.Context(), `
+		UPDATE vendor_kyc_documents SET removed_at = NOW() WHERE id = $1 AND vendor_id = $2 AND removed_at IS NULL
+	`, c.Param("id"), vendorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove document"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document removed"})
+}
+
+// POST /vendor/me/kyc/submit
+// Sends the current documents for review. Needs an ID proof and a GST
+// certificate or business registration.
+func (h *VendorHandler) SubmitKYC(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	vendorID, _, ok := requireVendorRole(c, vendorRoleOwner)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit documents"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var submittedAt *time.Time
+	err = tx.QueryRow(ctx, `SELECT status, kyc_submitted_at FROM vendor_profiles WHERE id = $1 FOR UPDATE`, vendorID).Scan(&status, &submittedAt)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor profile not found"})
+		return
+	}
+	if msg := kycLockedMessage(status, submittedAt); msg != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+		return
+	}
+
+	var documentIDs []string
+	var hasIDProof, hasBusinessProof bool
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(array_agg(id::text ORDER BY created_at), '{}'),
+		       bool_or(document_type = 'id_proof') IS TRUE,
+		       bool_or(document_type IN ('gst_certificate', 'business_registration')) IS TRUE
+		FROM vendor_kyc_documents WHERE vendor_id = $1 AND removed_at IS NULL
+	`, vendorID).Scan(&documentIDs, &hasIDProof, &hasBusinessProof)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit documents"})
+		return
+	}
+	if !hasIDProof || !hasBusinessProof {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload an ID proof and a GST certificate or business registration before submitting"})
+		return
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE vendor_profiles SET status = 'pending', kyc_submitted_at = NOW(), rejection_reason = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, vendorID)
+	if err == nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO vendor_kyc_reviews (vendor_id, action, document_ids, actor_user_id) VALUES ($1, 'submitted', $2::uuid[], $3)
+		`, vendorID, documentIDs, userID)
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to submit KYC: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit documents"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit documents"})
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('vendor', $1, 'kyc_submitted', $2)`, vendorID, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Documents submitted for review"})
+}
+
+// kycEditable checks that documents may be changed: not while a submission
+// awaits review and not once the vendor is verified. It writes the error
+// response itself when not.
+func kycEditable(c *gin.Context, vendorID string) bool {
+	var status string
+	var submittedAt *time.Time
+	err := db.Pool.QueryRow(c.Request.Context(), `SELECT status, kyc_submitted_at FROM vendor_profiles WHERE id = $1`, vendorID).Scan(&status, &submittedAt)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor profile not found"})
+		return false
+	}
+	if msg := kycLockedMessage(status, submittedAt); msg != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+		return false
+	}
+	return true
+}
+
+func kycLockedMessage(status string, submittedAt *time.Time) string {
+	switch {
+	case status == "verified":
+		return "Your business is already verified; contact support to update documents"
+	case status == "deleted":
+		return "This vendor profile has been deleted"
+	case submittedAt != nil:
+		return "Your documents are under review"
+	}
+	return ""
+}
+
+// loadKYCDocuments lists the vendor's documents, oldest first. Removed ones
+// are only included for reviewers.
+func loadKYCDocuments(ctx context.Context, vendorID string, includeRemoved bool) ([]KYCDocument, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id, document_type, file_name, content_type, size_bytes, created_at, removed_at, storage_key
+		FROM vendor_kyc_documents
+		WHERE vendor_id = $1 AND (removed_at IS NULL OR $2)
+		ORDER BY created_at
+	`, vendorID, includeRemoved)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []KYCDocument{}
+	for rows.Next() {
+		var d KYCDocument
+		if err := rows.Scan(&d.ID, &d.DocumentType, &d.FileName, &d.ContentType, &d.SizeBytes, &d.UploadedAt, &d.RemovedAt, &d.storageKey); err != nil {
+			return nil, err
+		}
+		documents = append(documents, d)
+	}
+	return documents, rows.Err()
+}
+
+// signKYCDocuments fills in short-lived download links. A document that
+// cannot be signed is listed without one.
+func signKYCDocuments(media *services.MediaService, ttl time.Duration, documents []KYCDocument) {
+	if media == nil {
+		return
+	}
+	for i := range documents {
+		url, err := media.PresignedURL(documents[i].storageKey, ttl)
+		if err != nil {
+			log.Printf("ERROR: Failed to sign KYC document %s: %v", documents[i].ID, err)
+			continue
+		}
+		documents[i].URL = url
+	}
+}
+
+// loadKYCHistory lists submissions and decisions, oldest first. Reviewer
+// names are only included for admins.
+func loadKYCHistory(ctx context.Context, vendorID string, withActors bool) ([]KYCReview, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT r.action, r.reason, r.document_ids::text[], u.full_name, r.created_at
+		FROM vendor_kyc_reviews r LEFT JOIN users u ON u.id = r.actor_user_id
+		WHERE r.vendor_id = $1
+		ORDER BY r.created_at
+	`, vendorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []KYCReview{}
+	for rows.Next() {
+		var r KYCReview
+		if err := rows.Scan(&r.Action, &r.Reason, &r.DocumentIDs, &r.ActorName, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		if !withActors {
+			r.ActorName = nil
+		}
+		history = append(history, r)
+	}
+	return history, rows.Err()
+}
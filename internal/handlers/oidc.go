@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/auth"
+	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/oidc"
+	"github.com/gin-gonic/gin"
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// GET /auth/oidc/providers
+func (h *AuthHandler) ListOIDCProviders(c *gin.Context) {
+	providers := []gin.H{}
+	for _, p := range h.Config.OIDCProviders {
+		providers = append(providers, gin.H{"name": p.Name})
+	}
+	c.JSON(http.StatusOK, providers)
+}
+
+// POST /auth/oidc/:provider/start
+// Returns the provider URL to send the browser to. The frontend page at the
+// provider's REDIRECT_URL then posts the returned code and state to the callback.
+func (h *AuthHandler) StartOIDCLogin(c *gin.Context) {
+	provider, ok := h.Config.OIDCProvider(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown login provider"})
+		return
+	}
+
+	state, err := oidc.RandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+	nonce, err := oidc.RandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+	verifier, err := oidc.RandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	authURL, err := oidc.ClientFor(provider).AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		log.Printf("ERROR: OIDC provider %s unavailable: %v", provider.Name, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Login provider is unavailable"})
+		return
+	}
+
+	// Opportunistic cleanup keeps the table small without a background job
+	_, _ = db.Pool.Exec(ctx, `DELETE FROM oidc_login_states WHERE expires_at < NOW() - INTERVAL '1 day'`)
+
+	insertQuery := `
+		INSERT INTO oidc_login_states (state_hash, provider, code_verifier, nonce, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = db.Pool.Exec(ctx, insertQuery, auth.HashToken(state), provider.Name, verifier, nonce, time.Now().Add(h.Config.OIDCStateTTL))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"authorization_url": authURL, "state": state})
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// POST /auth/oidc/:provider/callback
+// Completes login: verifies the ID token, then signs in the linked account,
+// links an existing account with the same verified email, or creates one.
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	provider, ok := h.Config.OIDCProvider(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown login provider"})
+		return
+	}
+
+	var req OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var verifier, nonce string
+	stateQuery := `
+		UPDATE oidc_login_states SET used_at = NOW()
+		WHERE state_hash = $1 AND provider = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING code_verifier, nonce
+	`
+	err := db.Pool.QueryRow(ctx, stateQuery, auth.HashToken(req.State), provider.Name).Scan(&verifier, &nonce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login request is invalid or has expired, please try again"})
+		return
+	}
+
+	client := oidc.ClientFor(provider)
+	rawIDToken, err := client.Exchange(ctx, req.Code, verifier)
+	if err != nil {
+		log.Printf("ERROR: OIDC code exchange with %s failed: %v", provider.Name, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login with provider failed"})
+		return
+	}
+	identity, err := client.VerifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		log.Printf("ERROR: OIDC id token from %s rejected: %v", provider.Name, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login with provider failed"})
+		return
+	}
+
+	userID, status, msg := h.resolveOIDCUser(ctx, provider.Name, identity)
+	if status != 0 {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+
+	var role, fullName string
+	var twoFactorEnabled bool
+	err = db.Pool.QueryRow(ctx, `SELECT role, full_name, totp_enabled_at IS NOT NULL FROM users WHERE id = $1`, userID).Scan(&role, &fullName, &twoFactorEnabled)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load account"})
+		return
+	}
+
+	// The provider replaces the password step only; 2FA still applies
+	if twoFactorEnabled {
+		challenge, err := auth.GenerateChallengeToken(userID, h.Config)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+		})
+		return
+	}
+
+	h.issueSession(c, userID, role, fullName)
+}
+
+// resolveOIDCUser maps a verified external identity to a user, linking or
+// creating one as needed. A non-zero status means the login must be refused.
+func (h *AuthHandler) resolveOIDCUser(ctx context.Context, provider string, identity *oidc.Identity) (string, int, string) {
+	var userID string
+	err := db.Pool.QueryRow(ctx, `
+		UPDATE user_identities SET last_login_at = NOW(), email = COALESCE(NULLIF($3, ''), email)
+		WHERE provider = $1 AND subject = $2
+		RETURNING user_id
+	`, provider, identity.Subject, identity.Email).Scan(&userID)
+	if err == nil {
+		return userID, 0, ""
+	}
+	if err != pgx.ErrNoRows {
+		return "", http.StatusInternalServerError, "Failed to look up account"
+	}
+
+	email := strings.TrimSpace(identity.Email)
+	if email == "" {
+		return "", http.StatusBadRequest, "The provider did not share an email address"
+	}
+
+	var localVerified bool
+	err = db.Pool.QueryRow(ctx, `SELECT id, email_verified_at IS NOT NULL FROM users WHERE lower(email) = lower($1)`, email).Scan(&userID, &localVerified)
+	if err == nil {
+		// Linking on an unverified address on either side would let whoever
+		// registered the address first take over the other party's account
+		if !identity.EmailVerified || !localVerified {
+			return "", http.StatusConflict, "An account with this email already exists. Sign in with your password to continue."
+		}
+		if status, msg := linkIdentity(ctx, userID, provider, identity, email); status != 0 {
+			return "", status, msg
+		}
+		return userID, 0, ""
+	}
+	if err != pgx.ErrNoRows {
+		return "", http.StatusInternalServerError, "Failed to look up account"
+	}
+
+	userID, err = h.createOIDCUser(ctx, provider, identity, email)
+	if err != nil {
+		log.Printf("ERROR: Failed to create account from %s login: %v", provider, err)
+		return "", http.StatusConflict, "Failed to create account"
+	}
+	return userID, 0, ""
+}
+
+func linkIdentity(ctx context.Context, userID, provider string, identity *oidc.Identity, email string) (int, string) {
+	tag, err := db.Pool.Exec(ctx, `
+		INSERT INTO user_identities (user_id, provider, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT DO NOTHING
+	`, userID, provider, identity.Subject, email)
+	if err != nil {
+		return http.StatusInternalServerError, "Failed to link account"
+	}
+	if tag.RowsAffected() == 0 {
+		return http.StatusConflict, "A different " + provider + " account is already linked to this user"
+	}
+
+	metadata := map[string]interface{}{"provider": provider}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'identity_linked', $1, $2)`, userID, metadata)
+	return 0, ""
+}
+
+func (h *AuthHandler) createOIDCUser(ctx context.Context, provider string, identity *oidc.Identity, email string) (string, error) {
+	fullName := strings.TrimSpace(identity.Name)
+	if fullName == "" {
+		// The address comes from the provider; don't assume it is well-formed
+		local, _, _ := strings.Cut(email, "@")
+		fullName = strings.TrimSpace(local)
+	}
+	if fullName == "" {
+		fullName = "User"
+	}
+	var verifiedAt interface{}
+	if identity.EmailVerified {
+		verifiedAt = time.Now()
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	// Empty password_hash: the account has no password until one is set via reset
+	var userID string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO users (email, password_hash, full_name, email_verified_at)
+		VALUES ($1, '', $2, $3)
+		RETURNING id
+	`, email, fullName, verifiedAt).Scan(&userID)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_identities (user_id, provider, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, userID, provider, identity.Subject, email)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+
+	metadata := map[string]interface{}{"provider": provider}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'identity_linked', $1, $2)`, userID, metadata)
+
+	if !identity.EmailVerified {
+		if err := h.sendVerificationEmail(context.Background(), userID, email); err != nil {
+			log.Printf("ERROR: Failed to issue verification email: %v", err)
+		}
+	}
+	return userID, nil
+}
+
+// GET /me/identities
+func (h *AuthHandler) ListIdentities(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	rows, err := db.Pool.Query(context.Background(), `
+		SELECT provider, email, created_at, last_login_at FROM user_identities
+		WHERE user_id = $1 ORDER BY created_at
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch identities"})
+		return
+	}
+	defer rows.Close()
+
+	identities := []gin.H{}
+	for rows.Next() {
+		var provider string
+		var email *string
+		var createdAt time.Time
+		var lastLoginAt *time.Time
+		if err := rows.Scan(&provider, &email, &createdAt, &lastLoginAt); err != nil {
+			continue
+		}
+		identities = append(identities, gin.H{
+			"provider":      provider,
+			"email":         email,
+			"created_at":    createdAt,
+			"last_login_at": lastLoginAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, identities)
+}
+
+// DELETE /me/identities/:provider
+// Refuses to remove the last way to sign in.
+func (h *AuthHandler) UnlinkIdentity(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	provider := c.Param("provider")
+	ctx := context.Background()
+
+	var hasPassword bool
+	var identityCount int
+	err := db.Pool.QueryRow(ctx, `
+		SELECT u.password_hash != '', (SELECT COUNT(*) FROM user_identities WHERE user_id = u.id)
+		FROM users u WHERE u.id = $1
+	`, userID).Scan(&hasPassword, &identityCount)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !hasPassword && identityCount <= 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set a password before removing your only sign-in method"})
+		return
+	}
+
+	tag, err := db.Pool.Exec(ctx, `DELETE FROM user_identities WHERE user_id = $1 AND provider = $2`, userID, provider)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink identity"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Identity not found"})
+		return
+	}
+
+	metadata := map[string]interface{}{"provider": provider}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('user', $1, 'identity_unlinked', $1, $2)`, userID, metadata)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Identity unlinked"})
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+const maxVendorPackages = 20
+
+// maxPackagePrice is the largest value starting_price (numeric(12,2)) can hold
+const maxPackagePrice = 9999999999.99
+
+var packageUnits = map[string]bool{
+	"per_event":  true,
+	"per_plate":  true,
+	"per_person": true,
+	"per_hour":   true,
+	"per_day":    true,
+}
+
+type VendorPackage struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Description   *string  `json:"description"`
+	Inclusions    []string `json:"inclusions"`
+	StartingPrice float64  `json:"starting_price"`
+	Unit          string   `json:"unit"`
+	IsActive      bool     `json:"is_active"`
+	SortOrder     int      `json:"sort_order"`
+}
+
+type VendorPackageRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Description   *string  `json:"description"`
+	Inclusions    []string `json:"inclusions"`
+	StartingPrice *float64 `json:"starting_price" binding:"required"`
+	Unit          string   `json:"unit" binding:"required"` // per_event, per_plate, per_person, per_hour or per_day
+	IsActive      *bool    `json:"is_active"`
+	SortOrder     int      `json:"sort_order"`
+}
+
+// validate trims the request and returns a user-facing error, if any
+func (r *VendorPackageRequest) validate() string {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || len(r.Name) > 120 {
+		return "name must be between 1 and 120 characters"
+	}
+	if *r.StartingPrice < 0 {
+		return "starting_price cannot be negative"
+	}
+	if *r.StartingPrice > maxPackagePrice {
+		return "starting_price cannot exceed 9999999999.99"
+	}
+	if r.Description != nil && len(*r.Description) > 2000 {
+		return "description must be at most 2000 characters"
+	}
+	if !packageUnits[r.Unit] {
+		return "unit must be one of per_event, per_plate, per_person, per_hour, per_day"
+	}
+	if len(r.Inclusions) > 50 {
+		return "A package can list at most 50 inclusions"
+	}
+	inclusions := make([]string, 0, len(r.Inclusions))
+	for _, item := range r.Inclusions {
+		item = strings.TrimSpace(item)
+		if len(item) > 200 {
+			return "Each inclusion must be at most 200 characters"
+		}
+		if item != "" {
+			inclusions = append(inclusions, item)
+		}
+	}
+	r.Inclusions = inclusions
+	if r.IsActive == nil {
+		active := true
+		r.IsActive = &active
+	}
+	return ""
+}
+
+// GET /vendor/me/packages
+// All of the vendor's packages, including inactive ones
+func (h *VendorHandler) ListMyPackages(c *gin.Context) {
+	vendorID, _, ok := requireVendorRole(c, vendorRoleResponder)
+	if !ok {
+		return
+	}
+
+	packages, err := loadVendorPackages(c.Request.Context(), vendorID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch packages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, packages)
+}
+
+// POST /vendor/me/packages
+func (h *VendorHandler) CreatePackage(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req VendorPackageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	vendorID, _, ok := requireVendorRole(c, vendorRoleManager)
+	if !ok {
+		return
+	}
+
+	var count int
+	if err := db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM vendor_packages WHERE vendor_id = $1", vendorID).Scan(&count); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create package"})
+		return
+	}
+	if count >= maxVendorPackages {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can have at most 20 packages"})
+		return
+	}
+
+	var id string
+	err := db.Pool.QueryRow(ctx, `
+		INSERT INTO vendor_packages (vendor_id, name, description, inclusions, starting_price, unit, is_active, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, vendorID, req.Name, req.Description, req.Inclusions, *req.StartingPrice, req.Unit, *req.IsActive, req.SortOrder).Scan(&id)
+	if err != nil {
+		log.Printf("ERROR: Failed to create package: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create package"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Package created", "id": id})
+}
+
+// PUT /vendor/me/packages/:id
+// Replaces the package. Quotes already sent keep their snapshot.
+func (h *VendorHandler) UpdatePackage(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req VendorPackageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	vendorID, _, ok := requireVendorRole(c, vendorRoleManager)
+	if !ok {
+		return
+	}
+
+	tag, err := db.Pool.Exec(ctx, `
+		UPDATE vendor_packages SET name = $3, description = $4, inclusions = $5, starting_price = $6,
+			unit = $7, is_active = $8, sort_order = $9, updated_at = NOW()
+		WHERE id = $1 AND vendor_id = $2
+	`, c.Param("id"), vendorID, req.Name, req.Description, req.Inclusions, *req.StartingPrice, req.Unit, *req.IsActive, req.SortOrder)
+	if err != nil {
+		log.Printf("ERROR: Failed to update package: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update package"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Package not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Package updated"})
+}
+
+// DELETE /vendor/me/packages/:id
+func (h *VendorHandler) DeletePackage(c *gin.Context) {
+	vendorID, _, ok := requireVendorRole(c, vendorRoleManager)
+	if !ok {
+		return
+	}
+
+	tag, err := db.Pool.Exec(c.Request.Context(), `
+		DELETE FROM vendor_packages WHERE id = $1 AND vendor_id = $2
+	`, c.Param("id"), vendorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete package"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Package not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Package deleted"})
+}
+
+func loadVendorPackages(ctx context.Context, vendorID string, activeOnly bool) ([]VendorPackage, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id, name, description, inclusions, starting_price::float8, unit, is_active, sort_order
+		FROM vendor_packages
+		WHERE vendor_id = $1 AND (is_active OR NOT $2)
+		ORDER BY sort_order, starting_price, created_at
+	`, vendorID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	packages := []VendorPackage{}
+	for rows.Next() {
+		var p VendorPackage
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Inclusions, &p.StartingPrice, &p.Unit, &p.IsActive, &p.SortOrder); err != nil {
+			return nil, err
+		}
+		packages = append(packages, p)
+	}
+	return packages, rows.Err()
+}
+
+// packageSnapshot captures an active package of the vendor as it is now, for
+// storing on a quote request. Packages of other vendors and inactive ones are
+// not found.
+func packageSnapshot(ctx context.Context, packageID, vendorID string) (map[string]interface{}, error) {
+	var snapshot map[string]interface{}
+	err := db.Pool.QueryRow(ctx, `
+		SELECT json_build_object(
+			'id', id, 'name', name, 'description', description, 'inclusions', inclusions,
+			'starting_price', starting_price, 'unit', unit
+		)
+		FROM vendor_packages WHERE id = $1 AND vendor_id = $2 AND is_active
+	`, packageID, vendorID).Scan(&snapshot)
+	return snapshot, err
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bventy/backend/internal/auth"
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// exportSections are the files in a personal data export. Each query selects
+// the user's rows; $1 is the user ID. Secrets (hashes, TOTP seeds) are never included.
+var exportSections = []struct {
+	File  string
+	Query string
+}{
+	{"profile.json", `
+		SELECT id, email, full_name, username, phone, city, bio, profile_image_url, role,
+		       email_verified_at, totp_enabled_at, created_at, updated_at, deletion_scheduled_for
+		FROM users WHERE id = $1`},
+	{"events.json", `SELECT * FROM events WHERE organizer_user_id = $1 ORDER BY created_at`},
+	{"groups.json", `
+		SELECT g.id, g.name, g.slug, g.description, g.city, gm.role AS membership_role,
+		       gm.created_at AS joined_at, g.owner_user_id = $1 AS is_owner
+		FROM groups g JOIN group_members gm ON gm.group_id = g.id
+		WHERE gm.user_id = $1 ORDER BY gm.created_at`},
+	{"shortlists.json", `
+		SELECT esv.event_id, esv.vendor_id, vp.business_name, esv.created_at
+		FROM event_shortlisted_vendors esv
+		JOIN events e ON e.id = esv.event_id
+		JOIN vendor_profiles vp ON vp.id = esv.vendor_id
+		WHERE e.organizer_user_id = $1 ORDER BY esv.created_at`},
+	{"quotes_requested.json", `SELECT * FROM quote_requests WHERE organizer_user_id = $1 ORDER BY created_at`},
+	{"vendor_profile.json", `SELECT * FROM vendor_profiles WHERE owner_user_id = $1`},
+	{"vendor_gallery.json", `
+		SELECT gi.* FROM vendor_gallery_images gi
+		JOIN vendor_profiles vp ON vp.id = gi.vendor_id WHERE vp.owner_user_id = $1 ORDER BY gi.sort_order`},
+	{"vendor_portfolio.json", `
+		SELECT pf.* FROM vendor_portfolio_files pf
+		JOIN vendor_profiles vp ON vp.id = pf.vendor_id WHERE vp.owner_user_id = $1 ORDER BY pf.sort_order`},
+	{"quotes_received.json", `
+		SELECT qr.* FROM quote_requests qr
+		JOIN vendor_profiles vp ON vp.id = qr.vendor_id WHERE vp.owner_user_id = $1 ORDER BY qr.created_at`},
+	{"vendor_kyc_documents.json", `
+		SELECT d.id, d.document_type, d.file_name, d.content_type, d.size_bytes, d.created_at, d.removed_at
+		FROM vendor_kyc_documents d JOIN vendor_profiles vp ON vp.id = d.vendor_id
+		WHERE vp.owner_user_id = $1 ORDER BY d.created_at`},
+	{"vendor_kyc_reviews.json", `
+		SELECT r.action, r.reason, r.document_ids, r.created_at
+		FROM vendor_kyc_reviews r JOIN vendor_profiles vp ON vp.id = r.vendor_id
+		WHERE vp.owner_user_id = $1 ORDER BY r.created_at`},
+	{"vendor_team.json", `
+		SELECT tm.vendor_id, vp.business_name, tm.role, tm.created_at AS joined_at
+		FROM vendor_team_members tm JOIN vendor_profiles vp ON vp.id = tm.vendor_id
+		WHERE tm.user_id = $1`},
+	{"reviews_written.json", `
+		SELECT r.id, r.quote_id, r.vendor_id, vp.business_name, r.rating, r.body, r.vendor_reply, r.status, r.created_at
+		FROM vendor_reviews r JOIN vendor_profiles vp ON vp.id = r.vendor_id
+		WHERE r.reviewer_user_id = $1 ORDER BY r.created_at`},
+	{"activity_log.json", `
+		SELECT * FROM platform_activity_log
+		WHERE actor_user_id = $1 OR (entity_type = 'user' AND entity_id::text = $1::text)
+		ORDER BY created_at`},
+	{"sessions.json", `
+		SELECT id, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at, revoked_reason
+		FROM user_sessions WHERE user_id = $1 AND impersonator_id IS NULL ORDER BY created_at`},
+	{"linked_identities.json", `SELECT provider, email, created_at, last_login_at FROM user_identities WHERE user_id = $1`},
+	{"api_keys.json", `
+		SELECT name, prefix, scopes, created_at, expires_at, last_used_at, last_used_ip, revoked_at
+		FROM api_keys WHERE user_id = $1 ORDER BY created_at`},
+}
+
+// GET /me/export
+// Streams a zip archive with one JSON file per kind of data we hold about the user.
+func (h *UserHandler) ExportMyData(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	ctx := context.Background()
+
+	filename := fmt.Sprintf("bventy-export-%s.zip", time.Now().Format("2006-01-02"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	zw := zip.NewWriter(c.Writer)
+	defer zw.Close()
+
+	manifest := gin.H{"user_id": userID, "generated_at": time.Now().UTC(), "files": []string{}}
+	files := []string{}
+
+	for _, section := range exportSections {
+		var data []byte
+		query := `SELECT COALESCE(json_agg(t), '[]'::json) FROM (` + section.Query + `) t`
+		if err := db.Pool.QueryRow(ctx, query, userID).Scan(&data); err != nil {
+			// A missing optional table must not break the whole export
+			log.Printf("ERROR: Export of %s failed for user %s: %v", section.File, userID, err)
+			continue
+		}
+
+		w, err := zw.Create(section.File)
+		if err != nil {
+			return
+		}
+		var pretty interface{}
+		if json.Unmarshal(data, &pretty) == nil {
+			data, _ = json.MarshalIndent(pretty, "", "  ")
+		}
+		_, _ = w.Write(data)
+		files = append(files, section.File)
+	}
+
+	manifest["files"] = files
+	if w, err := zw.Create("manifest.json"); err == nil {
+		data, _ := json.MarshalIndent(manifest, "", "  ")
+		_, _ = w.Write(data)
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'data_exported', $1)`, userID)
+}
+
+type DeleteAccountRequest struct {
+	// Required when the account has a password
+	Password string `json:"password"`
+	// Required for accounts without a password (social login only)
+	ConfirmEmail string `json:"confirm_email"`
+}
+
+// DELETE /me
+// Schedules the account for deletion after the grace period and signs out other devices.
+func (h *UserHandler) RequestAccountDeletion(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	var email, passwordHash, role string
+	err := db.Pool.QueryRow(ctx, `SELECT email, password_hash, role FROM users WHERE id = $1`, userID).Scan(&email, &passwordHash, &role)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if role == "super_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Super admin accounts cannot be deleted this way"})
+		return
+	}
+
+	if passwordHash != "" {
+		if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)) != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+			return
+		}
+	} else if req.ConfirmEmail != email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Type your account email to confirm deletion"})
+		return
+	}
+
+	var scheduledFor time.Time
+	updateQuery := `
+		UPDATE users SET deletion_requested_at = NOW(), deletion_scheduled_for = NOW() + make_interval(secs => $2)
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING deletion_scheduled_for
+	`
+	if err := db.Pool.QueryRow(ctx, updateQuery, userID, h.Config.AccountDeletionGracePeriod.Seconds()).Scan(&scheduledFor); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule deletion"})
+		return
+	}
+
+	if err := auth.RevokeUserSessions(ctx, userID, c.GetString("sessionID"), "account_deletion_requested"); err != nil {
+		log.Printf("ERROR: Failed to revoke sessions after deletion request: %v", err)
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'account_deletion_requested', $1)`, userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":                "Your account will be deleted after the grace period. Sign in and cancel before then to keep it.",
+		"deletion_scheduled_for": scheduledFor,
+	})
+}
+
+// POST /me/deletion/cancel
+func (h *UserHandler) CancelAccountDeletion(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	ctx := context.Background()
+
+	query := `
+		UPDATE users SET deletion_requested_at = NULL, deletion_scheduled_for = NULL
+		WHERE id = $1 AND deletion_scheduled_for IS NOT NULL AND deleted_at IS NULL
+		RETURNING id
+	`
+	var id string
+	if err := db.Pool.QueryRow(ctx, query, userID).Scan(&id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No deletion is scheduled for this account"})
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'account_deletion_cancelled', $1)`, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deletion cancelled"})
+}
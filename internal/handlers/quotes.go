@@ -6,19 +6,21 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/bventy/backend/internal/config"
 	"github.com/bventy/backend/internal/db"
 	"github.com/bventy/backend/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
 type QuotesHandler struct {
+	Config       *config.Config
 	MediaService *services.MediaService
 }
 
-func NewQuotesHandler() *QuotesHandler {
+func NewQuotesHandler(cfg *config.Config) *QuotesHandler {
 	// We might need media service here for some logic, but usually it's used in MediaHandler.
 	// For RespondToQuote, we might want to handle attachment verification if needed.
-	return &QuotesHandler{}
+	return &QuotesHandler{Config: cfg}
 }
 
 type CreateQuoteRequestPayload struct {
@@ -28,6 +30,7 @@ type CreateQuoteRequestPayload struct {
 	BudgetRange         *string `json:"budget_range"`
 	SpecialRequirements *string `json:"special_requirements"`
 	Deadline            *string `json:"deadline"` // ISO string
+	PackageID           *string `json:"package_id"`
 }
 
 type RevisionPayload struct {
@@ -71,19 +74,47 @@ func (h *QuotesHandler) CreateQuoteRequest(c *gin.Context) {
 		return
 	}
 
-	// 3. Insert quote request
+	// 3. Optional package the quote starts from, snapshotted as it is now
+	var packageID *string
+	var snapshot interface{} // left nil (SQL NULL) without a package
+	if payload.PackageID != nil && *payload.PackageID != "" {
+		pkg, err := packageSnapshot(ctx, *payload.PackageID, payload.VendorID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Package not found for this vendor"})
+			return
+		}
+		packageID, snapshot = payload.PackageID, pkg
+	}
+
+	// 4. Check the vendor's calendar for the event date
+	availability, eventDate, err := vendorAvailabilityForEvent(ctx, payload.VendorID, payload.EventID)
+	if err != nil {
+		log.Printf("ERROR: Failed to check vendor availability: %v", err)
+	}
+	var warning gin.H
+	if availability != "" {
+		message := "The vendor is marked " + availability + " on " + eventDate
+		// Tentative dates may still work out, so they only ever warn
+		if availability != "tentative" && h.Config.RefuseUnavailableQuotes {
+			c.JSON(http.StatusConflict, gin.H{"error": message, "code": "vendor_unavailable", "status": availability, "date": eventDate})
+			return
+		}
+		warning = gin.H{"code": "vendor_unavailable", "message": message, "status": availability, "date": eventDate}
+	}
+
+	// 5. Insert quote request
 	var quoteID string
 	insertQuoteQuery := `
 		INSERT INTO quote_requests (
 			event_id, vendor_id, organizer_user_id, message, budget_range, 
-			special_requirements, deadline, status
+			special_requirements, deadline, status, package_id, package_snapshot
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, 'pending')
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'pending', $8, $9)
 		RETURNING id
 	`
 	err = db.Pool.QueryRow(ctx, insertQuoteQuery,
 		payload.EventID, payload.VendorID, organizerID, payload.Message, payload.BudgetRange,
-		payload.SpecialRequirements, payload.Deadline,
+		payload.SpecialRequirements, payload.Deadline, packageID, snapshot,
 	).Scan(&quoteID)
 	if err != nil {
 		log.Printf("ERROR: Failed to create quote request: %v", err)
@@ -91,17 +122,21 @@ func (h *QuotesHandler) CreateQuoteRequest(c *gin.Context) {
 		return
 	}
 
-	// 4. Activity Log: Fire-and-forget
+	// 6. Activity Log: Fire-and-forget
 	insertLogQuery := `
 		INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id)
 		VALUES ('quote', $1, 'quote_created', $2)
 	`
 	_, _ = db.Pool.Exec(ctx, insertLogQuery, quoteID, organizerID)
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"message":  "Quote requested successfully",
 		"quote_id": quoteID,
-	})
+	}
+	if warning != nil {
+		response["warning"] = warning
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GET /quotes/vendor
@@ -115,11 +150,9 @@ func (h *QuotesHandler) GetVendorQuotes(c *gin.Context) {
 	ctx := c.Request.Context()
 	h.lazyUpdateQuotesAndEvents(ctx, userID.(string))
 
-	// Get vendor ID from userID
-	var vendorID string
-	err := db.Pool.QueryRow(ctx, "SELECT id FROM vendor_profiles WHERE owner_user_id = $1", userID.(string)).Scan(&vendorID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor profile not found for this user"})
+	// Any member of the vendor's team can see the inbox
+	vendorID, _, ok := requireVendorRole(c, vendorRoleResponder)
+	if !ok {
 		return
 	}
 
@@ -127,7 +160,7 @@ func (h *QuotesHandler) GetVendorQuotes(c *gin.Context) {
 		SELECT qr.id, qr.event_id, e.title as event_title, qr.organizer_user_id, u.full_name as organizer_name, 
 		       qr.message, qr.quoted_price, qr.vendor_response, qr.status, qr.responded_at, qr.created_at, qr.budget_range,
 		       qr.special_requirements, qr.deadline, qr.attachment_url, qr.accepted_at, qr.rejected_at, qr.revision_requested_at, qr.contact_unlocked_at,
-		       qr.contact_expires_at, qr.archived_at, qr.revision_message
+		       qr.contact_expires_at, qr.archived_at, qr.revision_message, qr.package_snapshot
 		FROM quote_requests qr
 		JOIN events e ON qr.event_id = e.id
 		JOIN users u ON qr.organizer_user_id = u.id
@@ -147,11 +180,12 @@ func (h *QuotesHandler) GetVendorQuotes(c *gin.Context) {
 		var message, vendorResponse, budgetRange, specialReq, attachmentURL, revisionMsg *string
 		var quotedPrice *float64
 		var respondedAt, createdAt, acceptedAt, rejectedAt, revisionAt, unlockedAt, expiresAt, archivedAt, deadline interface{}
+		var pkg map[string]interface{}
 
 		err := rows.Scan(
 			&id, &eventID, &eventTitle, &organizerID, &organizerName, &message, &quotedPrice, &vendorResponse, &status,
 			&respondedAt, &createdAt, &budgetRange, &specialReq, &deadline, &attachmentURL, &acceptedAt, &rejectedAt, &revisionAt, &unlockedAt,
-			&expiresAt, &archivedAt, &revisionMsg,
+			&expiresAt, &archivedAt, &revisionMsg, &pkg,
 		)
 		if err != nil {
 			log.Printf("Error scanning vendor quote row: %v", err)
@@ -181,6 +215,7 @@ func (h *QuotesHandler) GetVendorQuotes(c *gin.Context) {
 			"contact_expires_at":    expiresAt,
 			"archived_at":           archivedAt,
 			"revision_message":      revisionMsg,
+			"package":               pkg,
 		})
 	}
 	if quotes == nil {
@@ -205,7 +240,7 @@ func (h *QuotesHandler) GetOrganizerQuotes(c *gin.Context) {
 		SELECT qr.id, qr.event_id, e.title as event_title, qr.vendor_id, v.business_name as vendor_name, 
 		       qr.message, qr.quoted_price, qr.vendor_response, qr.status, qr.responded_at, qr.created_at, qr.budget_range,
 		       qr.special_requirements, qr.deadline, qr.attachment_url, qr.accepted_at, qr.rejected_at, qr.revision_requested_at, qr.contact_unlocked_at,
-		       qr.contact_expires_at, qr.archived_at, qr.revision_message
+		       qr.contact_expires_at, qr.archived_at, qr.revision_message, qr.package_snapshot
 		FROM quote_requests qr
 		JOIN events e ON qr.event_id = e.id
 		JOIN vendor_profiles v ON qr.vendor_id = v.id
@@ -225,11 +260,12 @@ func (h *QuotesHandler) GetOrganizerQuotes(c *gin.Context) {
 		var message, vendorResponse, budgetRange, specialReq, attachmentURL, revisionMsg *string
 		var quotedPrice *float64
 		var respondedAt, createdAt, acceptedAt, rejectedAt, revisionAt, unlockedAt, expiresAt, archivedAt, deadline interface{}
+		var pkg map[string]interface{}
 
 		err := rows.Scan(
 			&id, &eventID, &eventTitle, &vendorID, &vendorName, &message, &quotedPrice, &vendorResponse, &status,
 			&respondedAt, &createdAt, &budgetRange, &specialReq, &deadline, &attachmentURL, &acceptedAt, &rejectedAt, &revisionAt, &unlockedAt,
-			&expiresAt, &archivedAt, &revisionMsg,
+			&expiresAt, &archivedAt, &revisionMsg, &pkg,
 		)
 		if err != nil {
 			log.Printf("Error scanning organizer quote row: %v", err)
@@ -259,6 +295,7 @@ func (h *QuotesHandler) GetOrganizerQuotes(c *gin.Context) {
 			"contact_expires_at":    expiresAt,
 			"archived_at":           archivedAt,
 			"revision_message":      revisionMsg,
+			"package":               pkg,
 		})
 	}
 	if quotes == nil {
@@ -291,16 +328,14 @@ func (h *QuotesHandler) RespondToQuote(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
-	// Verify vendor owns this quote
-	var vendorID string
-	err := db.Pool.QueryRow(ctx, "SELECT id FROM vendor_profiles WHERE owner_user_id = $1", userID.(string)).Scan(&vendorID)
-	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only vendors can respond"})
+	// Verify the caller is on the team of the vendor the quote was sent to
+	vendorID, _, ok := requireVendorRole(c, vendorRoleResponder)
+	if !ok {
 		return
 	}
 
 	var quoteVendorID string
-	err = db.Pool.QueryRow(ctx, "SELECT vendor_id FROM quote_requests WHERE id = $1", quoteID).Scan(&quoteVendorID)
+	err := db.Pool.QueryRow(ctx, "SELECT vendor_id FROM quote_requests WHERE id = $1", quoteID).Scan(&quoteVendorID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
 		return
@@ -323,6 +358,9 @@ func (h *QuotesHandler) RespondToQuote(c *gin.Context) {
 		return
 	}
 
+	// Several team members can answer quotes; record who did
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('quote', $1, 'quote_responded', $2)`, quoteID, userID)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Quote responded successfully"})
 }
 
@@ -386,8 +424,7 @@ func (h *QuotesHandler) GetQuoteContact(c *gin.Context) {
 
 	// Check if user is the vendor
 	var isVendor bool
-	var actualVendorID string
-	_ = db.Pool.QueryRow(ctx, "SELECT id FROM vendor_profiles WHERE owner_user_id = $1", userID.(string)).Scan(&actualVendorID)
+	actualVendorID, _, _ := vendorMembership(ctx, userID.(string))
 	if actualVendorID == vendorID {
 		isVendor = true
 	}
@@ -513,6 +550,12 @@ func (h *QuotesHandler) updateQuoteStatusByOrganizer(c *gin.Context, newStatus s
 
 	if newStatus == "accepted" {
 		_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('quote', $1, 'contact_unlocked', $2)`, quoteID, organizerID)
+
+		if err := bookVendorForQuote(ctx, quoteID); err != nil {
+			log.Printf("ERROR: Failed to mark vendor booked for quote %s: %v", quoteID, err)
+		}
+	} else if err := releaseVendorForQuote(ctx, quoteID); err != nil {
+		log.Printf("ERROR: Failed to release vendor booking for quote %s: %v", quoteID, err)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Quote " + newStatus + " successfully"})
@@ -532,8 +575,7 @@ func (h *QuotesHandler) lazyUpdateQuotesAndEvents(ctx context.Context, userID st
 	// If userID is vendor, check quotes for their vendor_id.
 
 	// Check if user is vendor first
-	var vendorID string
-	_ = db.Pool.QueryRow(ctx, "SELECT id FROM vendor_profiles WHERE owner_user_id = $1", userID).Scan(&vendorID)
+	vendorID, _, _ := vendorMembership(ctx, userID)
 
 	archiveQuotesQuery := `
 		UPDATE quote_requests 
@@ -550,6 +592,9 @@ func (h *QuotesHandler) lazyUpdateQuotesAndEvents(ctx context.Context, userID st
 		for rows.Next() {
 			var quoteID string
 			if err := rows.Scan(&quoteID); err == nil {
+				if err := releaseVendorForQuote(ctx, quoteID); err != nil {
+					log.Printf("ERROR: Failed to release vendor booking for quote %s: %v", quoteID, err)
+				}
 				// Log expiry for analytics
 				queryLog := `INSERT INTO platform_activity_log (user_id, event_type, metadata, created_at) VALUES ($1, 'contact_expired', $2, NOW())`
 				metadata := fmt.Sprintf(`{"quote_id": "%s", "triggered_by": "%s"}`, quoteID, userID)
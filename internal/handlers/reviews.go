@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	maxReviewLength   = 2000
+	maxReviewPageSize = 50
+)
+
+type CreateReviewPayload struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Body   string `json:"body" binding:"required"`
+}
+
+// POST /quotes/:id/review (Organizers only)
+// Only the organizer of an accepted quote can review, and only once the event
+// has been completed. One review per quote.
+func (h *QuotesHandler) CreateReview(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	quoteID := c.Param("id")
+
+	var payload CreateReviewPayload
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body := strings.TrimSpace(payload.Body)
+	if body == "" || len(body) > maxReviewLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Review must be between 1 and 2000 characters"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Events past their date are only marked completed lazily
+	h.lazyUpdateQuotesAndEvents(ctx, userID)
+
+	// A booking is a quote the vendor answered and the organizer accepted (and
+	// did not reject afterwards), for an event held on or after the acceptance
+	var organizerID, vendorID, eventID, eventStatus string
+	var booked, heldAfterBooking bool
+	query := `
+		SELECT qr.organizer_user_id, qr.vendor_id, qr.event_id, COALESCE(e.status, ''),
+		       qr.accepted_at IS NOT NULL AND qr.responded_at IS NOT NULL AND qr.status IN ('accepted', 'archived'),
+		       COALESCE(e.event_date >= qr.accepted_at::date, false)
+		FROM quote_requests qr
+		JOIN events e ON e.id = qr.event_id
+		WHERE qr.id = $1
+	`
+	err := db.Pool.QueryRow(ctx, query, quoteID).Scan(&organizerID, &vendorID, &eventID, &eventStatus, &booked, &heldAfterBooking)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		return
+	}
+	if organizerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only review vendors you booked"})
+		return
+	}
+	if !booked {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only quotes the vendor answered and you accepted can be reviewed"})
+		return
+	}
+	if !heldAfterBooking {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only events held after the booking can be reviewed"})
+		return
+	}
+	if eventStatus != "completed" {
+		c.JSON(http.StatusConflict, gin.H{"error": "You can review this vendor once the event is completed"})
+		return
+	}
+
+	var reviewID string
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO vendor_reviews (quote_id, vendor_id, event_id, reviewer_user_id, rating, body)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, quoteID, vendorID, eventID, userID, payload.Rating, body).Scan(&reviewID)
+	if err != nil {
+		if strings.Contains(err.Error(), "unique constraint") {
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already reviewed this booking"})
+			return
+		}
+		log.Printf("ERROR: Failed to create review: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('vendor', $1, 'review_created', $2)`, vendorID, userID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Review submitted",
+		"review_id": reviewID,
+	})
+}
+
+// GET /vendors/slug/:slug/reviews?limit=&offset=
+// Published reviews, newest first, with the vendor's reply if any
+func (h *QuotesHandler) ListVendorReviews(c *gin.Context) {
+	limit := 20
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(n, maxReviewPageSize)
+	}
+	offset := 0
+	if raw := c.Query("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+		offset = n
+	}
+
+	ctx := c.Request.Context()
+
+	vendorID, _, err := resolveVendorSlug(ctx, c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	var ratingCount int
+	var ratingAverage *float64
+	err = db.Pool.QueryRow(ctx, `
+		SELECT rating_count, rating_average::float8 FROM vendor_profiles WHERE id = $1
+	`, vendorID).Scan(&ratingCount, &ratingAverage)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	query := `
+		SELECT r.id, r.rating, r.body, r.vendor_reply, r.vendor_replied_at, r.created_at,
+		       COALESCE(u.full_name, 'Deleted user'), u.profile_image_url, e.event_type
+		FROM vendor_reviews r
+		LEFT JOIN users u ON u.id = r.reviewer_user_id
+		LEFT JOIN events e ON e.id = r.event_id
+		WHERE r.vendor_id = $1 AND r.status = 'published'
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := db.Pool.Query(ctx, query, vendorID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+	defer rows.Close()
+
+	reviews := []gin.H{}
+	for rows.Next() {
+		var id, body, reviewerName string
+		var rating int
+		var reply, reviewerImage, eventType *string
+		var repliedAt *time.Time
+		var createdAt time.Time
+		if err := rows.Scan(&id, &rating, &body, &reply, &repliedAt, &createdAt, &reviewerName, &reviewerImage, &eventType); err != nil {
+			continue
+		}
+		reviews = append(reviews, gin.H{
+			"id":                id,
+			"rating":            rating,
+			"body":              body,
+			"vendor_reply":      reply,
+			"vendor_replied_at": repliedAt,
+			"created_at":        createdAt,
+			"reviewer_name":     reviewerName,
+			"reviewer_image":    reviewerImage,
+			"event_type":        eventType,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reviews":        reviews,
+		"rating_average": ratingAverage,
+		"rating_count":   ratingCount,
+		"limit":          limit,
+		"offset":         offset,
+	})
+}
+
+// PUT /reviews/:id/reply (Vendor managers and owner)
+// Sets or replaces the vendor's public reply to a review of their business
+func (h *QuotesHandler) ReplyToReview(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	reviewID := c.Param("id")
+
+	var input struct {
+		Reply string `json:"reply" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reply := strings.TrimSpace(input.Reply)
+	if reply == "" || len(reply) > maxReviewLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reply must be between 1 and 2000 characters"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	vendorID, _, ok := requireVendorRole(c, vendorRoleManager)
+	if !ok {
+		return
+	}
+
+	var reviewVendorID, status string
+	err := db.Pool.QueryRow(ctx, "SELECT vendor_id, status FROM vendor_reviews WHERE id = $1", reviewID).Scan(&reviewVendorID, &status)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	if reviewVendorID != vendorID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only reply to reviews of your business"})
+		return
+	}
+	if status != "published" {
+		c.JSON(http.StatusConflict, gin.H{"error": "This review has been hidden by a moderator"})
+		return
+	}
+
+	_, err = db.Pool.Exec(ctx, `
+		UPDATE vendor_reviews SET vendor_reply = $1, vendor_replied_at = NOW(), updated_at = NOW() WHERE id = $2
+	`, reply, reviewID)
+	if err != nil {
+		log.Printf("ERROR: Failed to save review reply: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reply"})
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('vendor', $1, 'review_replied', $2)`, vendorID, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reply saved"})
+}
+
+// POST /reviews/:id/report
+// Flags a review for moderation. Each user can report a review once.
+func (h *QuotesHandler) ReportReview(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	reviewID := c.Param("id")
+
+	var input struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" || len(reason) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason must be between 1 and 500 characters"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var status string
+	var reviewerID *string
+	err := db.Pool.QueryRow(ctx, "SELECT status, reviewer_user_id FROM vendor_reviews WHERE id = $1", reviewID).Scan(&status, &reviewerID)
+	if err == pgx.ErrNoRows || (err == nil && status != "published") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to report review"})
+		return
+	}
+	if reviewerID != nil && *reviewerID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot report your own review"})
+		return
+	}
+
+	tag, err := db.Pool.Exec(ctx, `
+		INSERT INTO vendor_review_reports (review_id, reporter_user_id, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (review_id, reporter_user_id) DO NOTHING
+	`, reviewID, userID, reason)
+	if err != nil {
+		log.Printf("ERROR: Failed to report review: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to report review"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already reported this review"})
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('review', $1, 'review_reported', $2)`, reviewID, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Thanks, a moderator will look at this review"})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bventy/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	suggestRefreshInterval = 5 * time.Minute
+	defaultSuggestPerType  = 5
+	maxSuggestPerType      = 10
+)
+
+type SearchHandler struct {
+	Suggestions *services.SuggestIndex
+}
+
+func NewSearchHandler() *SearchHandler {
+	return &SearchHandler{Suggestions: services.NewSuggestIndex(suggestRefreshInterval)}
+}
+
+// GET /search/suggest?q=&limit=
+// Typed autocomplete suggestions (vendors with slugs, categories, cities),
+// served from an in-memory index that refreshes every few minutes.
+func (h *SearchHandler) Suggest(c *gin.Context) {
+	q := c.Query("q")
+	if q == "" {
+		c.JSON(http.StatusOK, gin.H{"suggestions": []services.Suggestion{}})
+		return
+	}
+
+	perType := defaultSuggestPerType
+	if raw := c.Query("limit"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			perType = min(n, maxSuggestPerType)
+		}
+	}
+
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, gin.H{"suggestions": h.Suggestions.Suggest(c.Request.Context(), q, perType)})
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxServiceAreas     = 10
+	maxServiceRadiusKm  = 500
+	defaultGeoRadiusKm  = 30
+	maxGeoQueryRadiusKm = 500
+
+	// Slightly under the true 111.2 so bounding boxes err on the wide side
+	kmPerDegree = 111.0
+)
+
+type ServiceArea struct {
+	ID        string   `json:"id"`
+	City      string   `json:"city"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	RadiusKm  int      `json:"radius_km"`
+}
+
+type ServiceAreaInput struct {
+	City      string   `json:"city" binding:"required"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	RadiusKm  int      `json:"radius_km"` // how far the vendor travels from this point
+}
+
+type UpdateServiceAreasRequest struct {
+	Areas []ServiceAreaInput `json:"areas" binding:"required"`
+}
+
+// geoPoint is a location filter: everything within RadiusKm of Lat/Lng
+type geoPoint struct {
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
+}
+
+// GET /vendor/me/service-areas
+func (h *VendorHandler) GetMyServiceAreas(c *gin.Context) {
+	vendorID, _, ok := requireVendorRole(c, vendorRoleResponder)
+	if !ok {
+		return
+	}
+
+	areas, err := loadServiceAreas(c.Request.Context(), vendorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service areas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, areas)
+}
+
+// PUT /vendor/me/service-areas
+// Replaces the whole list. Areas without coordinates match by city name only.
+func (h *VendorHandler) UpdateMyServiceAreas(c *gin.Context) {
+	var req UpdateServiceAreasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Areas) == 0 || len(req.Areas) > maxServiceAreas {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide between 1 and 10 service areas"})
+		return
+	}
+	for i := range req.Areas {
+		if msg := req.Areas[i].validate(); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("areas[%d]: %s", i, msg)})
+			return
+		}
+	}
+
+	vendorID, _, ok := requireVendorRole(c, vendorRoleManager)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service areas"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM vendor_service_areas WHERE vendor_id = $1`, vendorID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service areas"})
+		return
+	}
+	for i, area := range req.Areas {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO vendor_service_areas (vendor_id, city, latitude, longitude, radius_km, sort_order)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, vendorID, area.City, area.Latitude, area.Longitude, area.RadiusKm, i)
+		if err != nil {
+			log.Printf("ERROR: Failed to save service area: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service areas"})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service areas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service areas updated"})
+}
+
+// validate trims the area and returns a user-facing error, if any
+func (a *ServiceAreaInput) validate() string {
+	a.City = strings.TrimSpace(a.City)
+	if a.City == "" || len(a.City) > 100 {
+		return "city must be between 1 and 100 characters"
+	}
+	if (a.Latitude == nil) != (a.Longitude == nil) {
+		return "latitude and longitude must be given together"
+	}
+	if a.Latitude != nil && (*a.Latitude < -90 || *a.Latitude > 90 || *a.Longitude < -180 || *a.Longitude > 180) {
+		return "latitude must be within ±90 and longitude within ±180"
+	}
+	if a.RadiusKm < 0 || a.RadiusKm > maxServiceRadiusKm {
+		return "radius_km must be between 0 and 500"
+	}
+	if a.Latitude == nil && a.RadiusKm > 0 {
+		return "radius_km needs latitude and longitude"
+	}
+	return ""
+}
+
+func loadServiceAreas(ctx context.Context, vendorID string) ([]ServiceArea, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id, city, latitude, longitude, radius_km FROM vendor_service_areas
+		WHERE vendor_id = $1
+		ORDER BY sort_order, created_at
+	`, vendorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	areas := []ServiceArea{}
+	for rows.Next() {
+		var a ServiceArea
+		if err := rows.Scan(&a.ID, &a.City, &a.Latitude, &a.Longitude, &a.RadiusKm); err != nil {
+			return nil, err
+		}
+		areas = append(areas, a)
+	}
+	return areas, rows.Err()
+}
+
+// geoQuery reads ?lat=&lng=&radius_km= (radius defaults to 30km). It returns
+// nil when no location was given and writes the error response itself when
+// the location is invalid.
+func geoQuery(c *gin.Context) (*geoPoint, bool) {
+	rawLat, rawLng := c.Query("lat"), c.Query("lng")
+	if rawLat == "" && rawLng == "" {
+		return nil, true
+	}
+
+	lat, errLat := strconv.ParseFloat(rawLat, 64)
+	lng, errLng := strconv.ParseFloat(rawLng, 64)
+	if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng must be given together as valid coordinates"})
+		return nil, false
+	}
+
+	radius, ok := geoRadius(c)
+	if !ok {
+		return nil, false
+	}
+	return &geoPoint{Lat: lat, Lng: lng, RadiusKm: radius}, true
+}
+
+// geoRadius reads ?radius_km=, defaulting to 30km
+func geoRadius(c *gin.Context) (float64, bool) {
+	radius := float64(defaultGeoRadiusKm)
+	if raw := c.Query("radius_km"); raw != "" {
+		r, err := strconv.ParseFloat(raw, 64)
+		if err != nil || r <= 0 || r > maxGeoQueryRadiusKm {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "radius_km must be between 0 and 500"})
+			return 0, false
+		}
+		radius = r
+	}
+	return radius, true
+}
+
+// servesPointSQL is a condition on vendor_profiles vp: one of the vendor's
+// service areas, widened by its travel radius, comes within radius of the
+// point. box is the matching areaBoxSQL prefilter; the other arguments are
+// SQL placeholders.
+func servesPointSQL(lat, lng, radius, box string) string {
+	return `EXISTS (SELECT 1 FROM vendor_service_areas sa WHERE sa.vendor_id = vp.id AND sa.latitude IS NOT NULL AND ` + box + `
+		AND distance_km(sa.latitude, sa.longitude, ` + lat + `::float8, ` + lng + `::float8) <= sa.radius_km + ` + radius + `::float8)`
+}
+
+// areaBoxSQL is a bounding-box condition on vendor_service_areas sa that the
+// (latitude, longitude) index can serve. It keeps every area that could reach
+// within radiusKm of the point, allowing for the largest travel radius.
+func areaBoxSQL(addArg func(interface{}) string, lat, lng, radiusKm float64) string {
+	km := radiusKm + maxServiceRadiusKm
+	dLat := km / kmPerDegree
+	minLat, maxLat := lat-dLat, lat+dLat
+	box := "sa.latitude BETWEEN " + addArg(minLat) + "::float8 AND " + addArg(maxLat) + "::float8"
+
+	// Longitude degrees shrink towards the poles; near them, latitude alone has to do
+	widest := math.Max(math.Abs(minLat), math.Abs(maxLat))
+	if widest >= 89 {
+		return box
+	}
+	dLng := dLat / math.Cos(widest*math.Pi/180)
+	if dLng >= 180 {
+		return box
+	}
+	minLng, maxLng := lng-dLng, lng+dLng
+	switch {
+	case minLng < -180: // the box crosses the antimeridian
+		return box + " AND (sa.longitude >= " + addArg(minLng+360) + "::float8 OR sa.longitude <= " + addArg(maxLng) + "::float8)"
+	case maxLng > 180:
+		return box + " AND (sa.longitude >= " + addArg(minLng) + "::float8 OR sa.longitude <= " + addArg(maxLng-360) + "::float8)"
+	}
+	return box + " AND sa.longitude BETWEEN " + addArg(minLng) + "::float8 AND " + addArg(maxLng) + "::float8"
+}
+
+// servesCitySQL is a condition on vendor_profiles vp: the profile city or one
+// of the service areas is the given city (an SQL placeholder).
+func servesCitySQL(city string) string {
+	return `(lower(vp.city) = lower(` + city + `) OR EXISTS (
+		SELECT 1 FROM vendor_service_areas sa WHERE sa.vendor_id = vp.id AND lower(sa.city) = lower(` + city + `)))`
+}
+
+// nearestAreaSQL selects the distance in km from the point to the vendor's
+// closest service area with coordinates (NULL when it has none).
+func nearestAreaSQL(lat, lng string) string {
+	return `(SELECT MIN(distance_km(sa.latitude, sa.longitude, ` + lat + `::float8, ` + lng + `::float8))
+		FROM vendor_service_areas sa WHERE sa.vendor_id = vp.id AND sa.latitude IS NOT NULL)`
+}
+
+// roundKm rounds a distance to 100m for display
+func roundKm(km *float64) *float64 {
+	if km == nil {
+		return nil
+	}
+	rounded := math.Round(*km*10) / 10
+	return &rounded
+}
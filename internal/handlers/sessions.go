@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/auth"
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+type SessionResponse struct {
+	ID         string     `json:"id"`
+	Device     string     `json:"device"`
+	UserAgent  *string    `json:"user_agent"`
+	IPAddress  *string    `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	Current    bool       `json:"current"`
+}
+
+// GET /me/sessions
+// Lists the user's signed-in devices. Impersonation sessions are not shown.
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	currentSessionID := c.GetString("sessionID")
+
+	query := `
+		SELECT id, user_agent, ip_address, created_at, last_seen_at
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW() AND impersonator_id IS NULL
+		ORDER BY last_seen_at DESC NULLS LAST
+	`
+	rows, err := db.Pool.Query(context.Background(), query, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	defer rows.Close()
+
+	sessions := []SessionResponse{}
+	for rows.Next() {
+		var s SessionResponse
+		if err := rows.Scan(&s.ID, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastSeenAt); err != nil {
+			continue
+		}
+		ua := ""
+		if s.UserAgent != nil {
+			ua = *s.UserAgent
+		}
+		s.Device = describeDevice(ua)
+		s.Current = s.ID == currentSessionID
+		sessions = append(sessions, s)
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// DELETE /me/sessions/:id
+func (h *AuthHandler) RevokeMySession(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	sessionID := c.Param("id")
+
+	query := `
+		UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = 'user_revoked'
+		WHERE id::text = $1 AND user_id = $2 AND revoked_at IS NULL AND impersonator_id IS NULL
+		RETURNING id
+	`
+	var id string
+	if err := db.Pool.QueryRow(context.Background(), query, sessionID, userID).Scan(&id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session signed out"})
+}
+
+// DELETE /me/sessions
+// Signs out every other device. Pass ?include_current=true to sign out this one too.
+func (h *AuthHandler) RevokeAllMySessions(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	keep := c.GetString("sessionID")
+	if c.Query("include_current") == "true" {
+		keep = ""
+	}
+
+	ctx := context.Background()
+	if err := auth.RevokeUserSessions(ctx, userID, keep, "signed_out_everywhere"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out sessions"})
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'signed_out_everywhere', $1)`, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Signed out of all other sessions"})
+}
+
+// describeDevice turns a user agent into a short label such as "Chrome on Windows".
+func describeDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "okhttp") || strings.Contains(ua, "dart") || strings.Contains(ua, "cfnetwork"):
+		browser = "App"
+	case strings.Contains(ua, "curl") || strings.Contains(ua, "postman"):
+		browser = "API client"
+	}
+
+	platform := ""
+	switch {
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad") || strings.Contains(ua, "ios"):
+		platform = "iOS"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "mac os") || strings.Contains(ua, "macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	}
+
+	if platform == "" {
+		return browser
+	}
+	return browser + " on " + platform
+}
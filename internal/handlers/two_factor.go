@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/bventy/backend/internal/auth"
+	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	pgx "github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// POST /me/2fa/enroll
+// Generates a new (not yet active) TOTP secret for the user to scan
+func (h *AuthHandler) EnrollTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	ctx := context.Background()
+
+	var email string
+	var enabledAt *time.Time
+	err := db.Pool.QueryRow(ctx, `SELECT email, totp_enabled_at FROM users WHERE id = $1`, userID).Scan(&email, &enabledAt)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if enabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	_, err = db.Pool.Exec(ctx, `UPDATE users SET totp_secret = $1, totp_last_step = 0 WHERE id = $2`, secret, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": auth.TOTPProvisioningURI(secret, email, h.Config.TOTPIssuer),
+	})
+}
+
+// POST /me/2fa/confirm
+// Activates 2FA once the user proves their app produces valid codes
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+
+	var secret *string
+	var enabledAt *time.Time
+	var lastStep int64
+	err := db.Pool.QueryRow(ctx, `SELECT totp_secret, totp_enabled_at, totp_last_step FROM users WHERE id = $1`, userID).Scan(&secret, &enabledAt, &lastStep)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if enabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if secret == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start enrollment first"})
+		return
+	}
+
+	step, ok := auth.ValidateTOTP(*secret, req.Code, lastStep, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `UPDATE users SET totp_enabled_at = NOW(), totp_last_step = $1 WHERE id = $2`, step, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'two_factor_enabled', $1)`, userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// POST /me/2fa/disable
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	role := c.MustGet("role").(string)
+
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.Config.Require2FAForAdmins && middleware.RoleAtLeast(role, "admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is mandatory for your role"})
+		return
+	}
+
+	ctx := context.Background()
+
+	var passwordHash string
+	if err := db.Pool.QueryRow(ctx, `SELECT password_hash FROM users WHERE id = $1`, userID).Scan(&passwordHash); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)) != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+
+	if ok, err := verifySecondFactor(ctx, userID, req.Code); err != nil || !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	_, err := db.Pool.Exec(ctx, `UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0 WHERE id = $1`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+	_, _ = db.Pool.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'two_factor_disabled', $1)`, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// POST /me/2fa/recovery-codes
+// Replaces all recovery codes; the old ones stop working
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	if ok, err := verifySecondFactor(ctx, userID, req.Code); err != nil || !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	codes, err := replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // TOTP or recovery code
+}
+
+// POST /auth/login/2fa
+// Second step of login for accounts with 2FA enabled
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := auth.ValidateChallengeToken(req.ChallengeToken, h.Config)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login challenge is invalid or has expired"})
+		return
+	}
+
+	ctx := context.Background()
+
+	var email, role, fullName string
+	if err := db.Pool.QueryRow(ctx, `SELECT email, role, full_name FROM users WHERE id = $1`, userID).Scan(&email, &role, &fullName); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+
+	// Code guesses share the account's login throttle
+	throttle := auth.CheckLoginAllowed(ctx, email, c.ClientIP())
+	if !throttle.Allowed() {
+		respondLoginThrottled(c, throttle)
+		return
+	}
+
+	ok, err := verifySecondFactor(ctx, userID, req.Code)
+	if err != nil || !ok {
+		h.recordLoginFailure(c, email, userID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	auth.ResetLoginFailures(ctx, email)
+	h.issueSession(c, userID, role, fullName)
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery code.
+// Accepted TOTP steps and recovery codes are consumed so they cannot be replayed.
+func verifySecondFactor(ctx context.Context, userID, code string) (bool, error) {
+	var secret *string
+	var lastStep int64
+	err := db.Pool.QueryRow(ctx, `SELECT totp_secret, totp_last_step FROM users WHERE id = $1 AND totp_enabled_at IS NOT NULL`, userID).Scan(&secret, &lastStep)
+	if err != nil || secret == nil {
+		return false, err
+	}
+
+	if step, ok := auth.ValidateTOTP(*secret, code, lastStep, time.Now()); ok {
+		// Conditional update wins the race if the same code is submitted twice
+		tag, err := db.Pool.Exec(ctx, `UPDATE users SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1`, step, userID)
+		if err != nil {
+			return false, err
+		}
+		return tag.RowsAffected() == 1, nil
+	}
+
+	tag, err := db.Pool.Exec(ctx, `
+		UPDATE user_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, auth.HashToken(auth.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 1 {
+		_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'recovery_code_used', $1)`, userID)
+		return true, nil
+	}
+	return false, nil
+}
+
+// replaceRecoveryCodes swaps the user's recovery codes for a fresh set within tx
+// and returns the plaintext codes, which are shown to the user exactly once.
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if _, err := tx.Exec(ctx, `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, auth.HashToken(code)); err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
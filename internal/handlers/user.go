@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/bventy/backend/internal/config"
 	"github.com/bventy/backend/internal/db"
@@ -37,7 +38,7 @@ func (h *UserHandler) PromoteToAdmin(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot change role of super_admin"})
 		return
 	}
-	_, err = db.Pool.Exec(context.Background(), "UPDATE users SET role='admin' WHERE id=$1", targetUserID)
+	_, err = db.Pool.Exec(context.Background(), "UPDATE users SET role='admin', token_version = token_version + 1 WHERE id=$1", targetUserID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to promote user"})
 		return
@@ -58,7 +59,7 @@ func (h *UserHandler) PromoteToStaff(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot demote/change admin users via this endpoint"})
 		return
 	}
-	_, err = db.Pool.Exec(context.Background(), "UPDATE users SET role='staff' WHERE id=$1", targetUserID)
+	_, err = db.Pool.Exec(context.Background(), "UPDATE users SET role='staff', token_version = token_version + 1 WHERE id=$1", targetUserID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to promote user"})
 		return
@@ -76,19 +77,20 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 	// Fetch user details
 	var email, role, fullName string
 	var username, profileImageURL *string // Use pointer for nullable string
+	var emailVerified bool
+	var deletionScheduledFor *time.Time
 
-	query := `SELECT email, role, full_name, username, profile_image_url FROM users WHERE id=$1`
-	err := db.Pool.QueryRow(context.Background(), query, userID).Scan(&email, &role, &fullName, &username, &profileImageURL)
+	query := `SELECT email, role, full_name, username, profile_image_url, email_verified_at IS NOT NULL, deletion_scheduled_for FROM users WHERE id=$1`
+	err := db.Pool.QueryRow(context.Background(), query, userID).Scan(&email, &role, &fullName, &username, &profileImageURL, &emailVerified, &deletionScheduledFor)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	// Check profiles
-	var vendorExists bool
-	var dummy int
-	err = db.Pool.QueryRow(context.Background(), "SELECT 1 FROM vendor_profiles WHERE owner_user_id=$1", userID).Scan(&dummy)
-	vendorExists = err == nil
+	// Check profiles (owners and other members of a vendor team alike)
+	var vendorRole *string
+	_ = db.Pool.QueryRow(context.Background(), "SELECT role FROM vendor_team_members WHERE user_id=$1", userID).Scan(&vendorRole)
+	vendorExists := vendorRole != nil
 
 	// Fetch groups
 	var groups []gin.H
@@ -111,14 +113,17 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":                    userID, // Added ID to response as it's useful
-		"email":                 email,
-		"full_name":             fullName,
-		"username":              username,        // Returns string or null
-		"profile_image_url":     profileImageURL, // Returns string or null
-		"role":                  role,
-		"vendor_profile_exists": vendorExists,
-		"groups":                groups,
+		"id":                     userID, // Added ID to response as it's useful
+		"email":                  email,
+		"email_verified":         emailVerified,
+		"full_name":              fullName,
+		"username":               username,        // Returns string or null
+		"profile_image_url":      profileImageURL, // Returns string or null
+		"role":                   role,
+		"vendor_profile_exists":  vendorExists,
+		"vendor_role":            vendorRole,
+		"groups":                 groups,
+		"deletion_scheduled_for": deletionScheduledFor,
 	})
 }
 
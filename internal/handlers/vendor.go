@@ -2,19 +2,27 @@ package handlers
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bventy/backend/internal/config"
 	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/mailer"
 	"github.com/bventy/backend/internal/services"
+	"github.com/bventy/backend/internal/slug"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type VendorHandler struct {
 	Config       *config.Config
 	MediaService *services.MediaService
+	Mailer       mailer.Mailer
 }
 
 func NewVendorHandler(cfg *config.Config) *VendorHandler {
@@ -22,6 +30,7 @@ func NewVendorHandler(cfg *config.Config) *VendorHandler {
 	return &VendorHandler{
 		Config:       cfg,
 		MediaService: svc,
+		Mailer:       mailer.New(cfg),
 	}
 }
 
@@ -46,52 +55,74 @@ func (h *VendorHandler) OnboardVendor(c *gin.Context) {
 		return
 	}
 
-	slug := generateSlug(req.BusinessName, req.City)
+	// Team members work for someone else's vendor profile
+	if _, _, err := vendorMembership(c.Request.Context(), userID.(string)); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "You are already on a vendor team. Leave it before creating your own profile."})
+		return
+	}
 
-	// Insert into vendor_profiles
+	// Insert into vendor_profiles. Slugs retired by other vendors still
+	// redirect to them, so those count as taken too.
 	query := `
 		INSERT INTO vendor_profiles (owner_user_id, business_name, slug, category, city, bio, whatsapp_link, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, 'pending')
+		SELECT $1, $2, $3, $4, $5, $6, $7, 'pending'
+		WHERE NOT EXISTS (SELECT 1 FROM vendor_slug_history WHERE slug = $3)
 		RETURNING id
 	`
 
 	var vendorID string
-	err := db.Pool.QueryRow(context.Background(), query, userID, req.BusinessName, slug, req.Category, req.City, req.Bio, req.WhatsappLink).Scan(&vendorID)
+	vendorSlug, err := slug.Allocate(slug.Make(req.BusinessName, req.City), "vendor", func(candidate string) error {
+		err := db.Pool.QueryRow(context.Background(), query, userID, req.BusinessName, candidate, req.Category, req.City, req.Bio, req.WhatsappLink).Scan(&vendorID)
+		if err == pgx.ErrNoRows || (err != nil && strings.Contains(err.Error(), "vendor_profiles_slug_key")) {
+			return slug.ErrTaken
+		}
+		return err
+	})
 	if err != nil {
 		if strings.Contains(err.Error(), "unique constraint") {
-			c.JSON(http.StatusConflict, gin.H{"error": "Vendor profile already exists for this user or slug conflict"})
+			c.JSON(http.StatusConflict, gin.H{"error": "Vendor profile already exists for this user"})
+			return
+		}
+		if errors.Is(err, slug.ErrTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Could not find a free URL for this business name, please try again"})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to onboard vendor: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Vendor profile created successfully", "vendor_id": vendorID, "slug": slug})
+	// The profile city is the first service area; more can be added later
+	_, _ = db.Pool.Exec(context.Background(), `INSERT INTO vendor_service_areas (vendor_id, city) VALUES ($1, $2)`, vendorID, req.City)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Vendor profile created successfully", "vendor_id": vendorID, "slug": vendorSlug})
 }
 
 func (h *VendorHandler) GetMyProfile(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	vendorID, role, ok := requireVendorRole(c, vendorRoleResponder)
+	if !ok {
 		return
 	}
 
 	// Use COALESCE for nullable text fields to avoid Scan errors
 	// Use 'status' column instead of non-existent 'verified' column
 	query := `
-		SELECT business_name, slug, category, city, COALESCE(bio, ''), whatsapp_link, portfolio_image_url, gallery_images, portfolio_files, status
+		SELECT business_name, slug, category, city, COALESCE(bio, ''), whatsapp_link, portfolio_image_url, gallery_images, portfolio_files, status,
+		       kyc_submitted_at IS NOT NULL, rejection_reason
 		FROM vendor_profiles 
-		WHERE owner_user_id = $1
+		WHERE id = $1
 	`
 
 	var name, slug, category, city, bio, whatsappLink, status string
 	var portfolioImageURL *string
 	var galleryImages []string
 	var portfolioFiles []interface{}
+	var kycSubmitted bool
+	var rejectionReason *string
 
-	err := db.Pool.QueryRow(context.Background(), query, userID).Scan(
+	err := db.Pool.QueryRow(context.Background(), query, vendorID).Scan(
 		&name, &slug, &category, &city, &bio, &whatsappLink,
 		&portfolioImageURL, &galleryImages, &portfolioFiles, &status,
+		&kycSubmitted, &rejectionReason,
 	)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor profile not found"})
@@ -102,6 +133,7 @@ func (h *VendorHandler) GetMyProfile(c *gin.Context) {
 	verified := (status == "verified")
 
 	c.JSON(http.StatusOK, gin.H{
+		"id":                  vendorID,
 		"business_name":       name,
 		"slug":                slug,
 		"category":            category,
@@ -112,34 +144,154 @@ func (h *VendorHandler) GetMyProfile(c *gin.Context) {
 		"gallery_images":      galleryImages,
 		"portfolio_files":     portfolioFiles,
 		"verified":            verified,
+		"status":              status,
+		"kyc_submitted":       kycSubmitted,
+		"rejection_reason":    rejectionReason,
+		"team_role":           role,
 	})
 }
 
+const (
+	defaultVendorPageSize = 20
+	maxVendorPageSize     = 100
+)
+
+// Sort orders for the public directory. Each maps to an indexed column;
+// id breaks ties so the keyset cursor is stable.
+var vendorSortColumns = map[string]string{
+	"newest":           "vp.created_at",
+	"most_shortlisted": "vp.shortlist_count",
+	"most_viewed":      "vp.view_count",
+}
+
+// vendorDirectoryTextSQL is the text the directory's q filter searches. It must
+// match the expression of idx_vendor_dir_text (migration 035) to use the index.
+const vendorDirectoryTextSQL = `lower(coalesce(vp.business_name, '') || E'\x1f' || coalesce(vp.category, '') || E'\x1f' || coalesce(vp.city, '') || E'\x1f' || coalesce(vp.bio, ''))`
+
+// GET /vendors?category=&city=&q=&lat=&lng=&radius_km=&sort=newest|most_shortlisted|most_viewed&limit=&cursor=
+// city matches service areas as well as the profile city; lat/lng keeps vendors
+// whose service areas reach within radius_km (default 30) of the point.
 func (h *VendorHandler) ListVerifiedVendors(c *gin.Context) {
+	sort := c.DefaultQuery("sort", "newest")
+	sortColumn, ok := vendorSortColumns[sort]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be one of newest, most_shortlisted, most_viewed"})
+		return
+	}
+
+	limit := defaultVendorPageSize
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(n, maxVendorPageSize)
+	}
+
+	point, ok := geoQuery(c)
+	if !ok {
+		return
+	}
+
+	// Filters shared by the page query and the total count
+	where := []string{"vp.status = 'verified'"}
+	args := []interface{}{}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if category := strings.TrimSpace(c.Query("category")); category != "" {
+		where = append(where, "lower(vp.category) = lower("+addArg(category)+")")
+	}
+	if city := strings.TrimSpace(c.Query("city")); city != "" {
+		where = append(where, servesCitySQL(addArg(city)))
+	}
+	distance := "NULL::float8"
+	if point != nil {
+		lat, lng := addArg(point.Lat), addArg(point.Lng)
+		box := areaBoxSQL(addArg, point.Lat, point.Lng, point.RadiusKm)
+		where = append(where, servesPointSQL(lat, lng, addArg(point.RadiusKm), box))
+		distance = nearestAreaSQL(lat, lng)
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		where = append(where, vendorDirectoryTextSQL+" LIKE lower("+addArg("%"+escapeLike(q)+"%")+")")
+	}
+
+	ctx := context.Background()
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM vendor_profiles vp WHERE ` + strings.Join(where, " AND ")
+	if err := db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch vendors"})
+		return
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		value, id, err := decodeVendorCursor(cursor, sort)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		cast := "::bigint"
+		if sort == "newest" {
+			cast = "::timestamp"
+		}
+		where = append(where, "("+sortColumn+", vp.id) < ("+addArg(value)+cast+", "+addArg(id)+"::uuid)")
+	}
+
 	query := `
 		SELECT 
 			vp.id, vp.business_name, vp.slug, vp.category, vp.city, vp.bio, vp.whatsapp_link, vp.portfolio_image_url, vp.gallery_images,
-			u.full_name, u.profile_image_url
+			u.full_name, u.profile_image_url, vp.shortlist_count, vp.view_count, vp.created_at,
+			vp.rating_count, vp.rating_average::float8, ` + distance + `
 		FROM vendor_profiles vp
 		JOIN users u ON vp.owner_user_id = u.id
-		WHERE vp.status = 'verified'
-	`
-	rows, err := db.Pool.Query(context.Background(), query)
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY ` + sortColumn + ` DESC, vp.id DESC
+		LIMIT ` + addArg(limit+1)
+
+	rows, err := db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch vendors"})
 		return
 	}
 	defer rows.Close()
 
-	var vendors []gin.H
+	vendors := []gin.H{}
+	var nextCursor *string
+	var lastSortValue, lastID string
 	for rows.Next() {
 		var id, name, slug, category, city, bio, whatsappLink string
 		var portfolioImageURL, ownerFullName, ownerProfileImage *string
 		var galleryImages []string
-		if err := rows.Scan(&id, &name, &slug, &category, &city, &bio, &whatsappLink, &portfolioImageURL, &galleryImages, &ownerFullName, &ownerProfileImage); err != nil {
+		var shortlistCount, viewCount int64
+		var createdAt time.Time
+		var ratingCount int
+		var ratingAverage, distanceKm *float64
+		if err := rows.Scan(&id, &name, &slug, &category, &city, &bio, &whatsappLink, &portfolioImageURL, &galleryImages, &ownerFullName, &ownerProfileImage, &shortlistCount, &viewCount, &createdAt, &ratingCount, &ratingAverage, &distanceKm); err != nil {
 			continue
 		}
-		vendors = append(vendors, gin.H{
+
+		// The extra row only tells us there is another page
+		if len(vendors) == limit {
+			cursor := encodeVendorCursor(sort, lastSortValue, lastID)
+			nextCursor = &cursor
+			break
+		}
+
+		lastID = id
+		switch sort {
+		case "most_shortlisted":
+			lastSortValue = strconv.FormatInt(shortlistCount, 10)
+		case "most_viewed":
+			lastSortValue = strconv.FormatInt(viewCount, 10)
+		default:
+			lastSortValue = createdAt.Format(time.RFC3339Nano)
+		}
+
+		vendor := gin.H{
 			"id":                  id,
 			"business_name":       name,
 			"slug":                slug,
@@ -151,38 +303,112 @@ func (h *VendorHandler) ListVerifiedVendors(c *gin.Context) {
 			"gallery_images":      galleryImages,
 			"owner_full_name":     ownerFullName,
 			"owner_profile_image": ownerProfileImage,
-		})
+			"shortlist_count":     shortlistCount,
+			"view_count":          viewCount,
+			"rating_average":      ratingAverage,
+			"rating_count":        ratingCount,
+		}
+		if point != nil {
+			vendor["distance_km"] = roundKm(distanceKm)
+		}
+		vendors = append(vendors, vendor)
 	}
 
-	c.JSON(http.StatusOK, vendors)
+	c.JSON(http.StatusOK, gin.H{
+		"vendors":     vendors,
+		"total":       total,
+		"next_cursor": nextCursor,
+	})
+}
+
+// Cursors are opaque to clients: base64("sort|value|id")
+func encodeVendorCursor(sort, value, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(sort + "|" + value + "|" + id))
 }
 
+func decodeVendorCursor(cursor, sort string) (string, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 || parts[0] != sort {
+		return "", "", errors.New("cursor does not match sort order")
+	}
+	if sort == "newest" {
+		if _, err := time.Parse(time.RFC3339Nano, parts[1]); err != nil {
+			return "", "", err
+		}
+	} else if _, err := strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return "", "", err
+	}
+	return parts[1], parts[2], nil
+}
+
+// escapeLike makes user input literal inside an ILIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// GET /vendors/slug/:slug
+// Retired slugs resolve too. The response always carries the current slug, and
+// redirected_from is set when the request used an old one so the frontend can 301.
 func (h *VendorHandler) GetVendorBySlug(c *gin.Context) {
 	slug := c.Param("slug")
+
+	vendorID, canonical, err := resolveVendorSlug(c.Request.Context(), slug)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+	var redirectedFrom *string
+	if canonical != slug {
+		redirectedFrom = &slug
+	}
+
 	query := `
 		SELECT 
 			vp.id, vp.business_name, vp.slug, vp.category, vp.city, vp.bio, vp.whatsapp_link, vp.portfolio_image_url, vp.gallery_images, vp.portfolio_files,
-			u.full_name, u.profile_image_url
+			u.full_name, u.profile_image_url, vp.rating_count, vp.rating_average::float8
 		FROM vendor_profiles vp
 		JOIN users u ON vp.owner_user_id = u.id
-		WHERE vp.slug = $1 AND vp.status = 'verified'
+		WHERE vp.id = $1
 	`
 
 	var id, name, s, category, city, bio, whatsappLink string
 	var portfolioImageURL, ownerFullName, ownerProfileImage *string
 	var galleryImages []string
 	var portfolioFiles []interface{}
+	var ratingCount int
+	var ratingAverage *float64
 
-	err := db.Pool.QueryRow(context.Background(), query, slug).Scan(
+	err = db.Pool.QueryRow(context.Background(), query, vendorID).Scan(
 		&id, &name, &s, &category, &city, &bio, &whatsappLink,
 		&portfolioImageURL, &galleryImages, &portfolioFiles,
-		&ownerFullName, &ownerProfileImage,
+		&ownerFullName, &ownerProfileImage, &ratingCount, &ratingAverage,
 	)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
 		return
 	}
 
+	// Unavailable dates for the coming months; the full calendar is at /vendors/slug/:slug/availability
+	today := time.Now().Truncate(24 * time.Hour)
+	availability, err := loadAvailability(c.Request.Context(), id, today, today.AddDate(0, 0, defaultAvailabilityDays), false)
+	if err != nil {
+		availability = []AvailabilityEntry{}
+	}
+
+	packages, err := loadVendorPackages(c.Request.Context(), id, true)
+	if err != nil {
+		packages = []VendorPackage{}
+	}
+
+	serviceAreas, err := loadServiceAreas(c.Request.Context(), id)
+	if err != nil {
+		serviceAreas = []ServiceArea{}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"id":                  id,
 		"business_name":       name,
@@ -196,6 +422,12 @@ func (h *VendorHandler) GetVendorBySlug(c *gin.Context) {
 		"portfolio_files":     portfolioFiles,
 		"owner_full_name":     ownerFullName,
 		"owner_profile_image": ownerProfileImage,
+		"rating_average":      ratingAverage,
+		"rating_count":        ratingCount,
+		"availability":        availability,
+		"packages":            packages,
+		"service_areas":       serviceAreas,
+		"redirected_from":     redirectedFrom,
 	})
 }
 
@@ -211,18 +443,17 @@ type UpdateVendorRequest struct {
 }
 
 func (h *VendorHandler) UpdateVendor(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
 	var req UpdateVendorRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	vendorID, _, ok := requireVendorRole(c, vendorRoleManager)
+	if !ok {
+		return
+	}
+
 	// Calculate new slug if business name changes?
 	// For simplicity, let's keep slug persistent or only update if explicitly needed.
 	// The requirement doesn't specify slug updates, so we'll skip slug updates to avoid breaking links.
@@ -233,7 +464,7 @@ func (h *VendorHandler) UpdateVendor(c *gin.Context) {
 	// However, it's safer to just pass it directly if the driver supports it.
 
 	// Handle Updates
-	// We need to verify ownership first or just update where owner_user_id = userID
+	// Team membership was checked above, so update by vendor id
 	query := `
 		UPDATE vendor_profiles 
 		SET business_name = COALESCE(NULLIF($2, ''), business_name),
@@ -244,7 +475,7 @@ func (h *VendorHandler) UpdateVendor(c *gin.Context) {
 		    portfolio_image_url = $7,
 		    gallery_images = $8,
 		    portfolio_files = $9
-		WHERE owner_user_id = $1
+		WHERE id = $1
 		RETURNING id
 	`
 
@@ -255,7 +486,7 @@ func (h *VendorHandler) UpdateVendor(c *gin.Context) {
 
 	var id string
 	err := db.Pool.QueryRow(context.Background(), query,
-		userID,
+		vendorID,
 		req.BusinessName,
 		req.Category,
 		req.City,
@@ -277,23 +508,13 @@ func (h *VendorHandler) UpdateVendor(c *gin.Context) {
 // UploadGalleryImage adds an image to the vendor's gallery
 func (h *VendorHandler) UploadGalleryImage(c *gin.Context) {
 	vendorID := c.Param("id")
-	userID := c.MustGet("userID").(string)
-
-	// Validate ownership
-	var ownerID string
-	err := db.Pool.QueryRow(context.TODO(), "SELECT owner_user_id FROM vendor_profiles WHERE id=$1", vendorID).Scan(&ownerID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
-		return
-	}
-	if ownerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not own this vendor profile"})
+	if !requireVendorRoleFor(c, vendorID, vendorRoleManager) {
 		return
 	}
 
 	// Check limit (25)
 	var count int
-	err = db.Pool.QueryRow(context.TODO(), "SELECT COUNT(*) FROM vendor_gallery_images WHERE vendor_id=$1", vendorID).Scan(&count)
+	err := db.Pool.QueryRow(context.TODO(), "SELECT COUNT(*) FROM vendor_gallery_images WHERE vendor_id=$1", vendorID).Scan(&count)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
@@ -346,23 +567,13 @@ func (h *VendorHandler) UploadGalleryImage(c *gin.Context) {
 func (h *VendorHandler) DeleteGalleryImage(c *gin.Context) {
 	vendorID := c.Param("id")
 	imageID := c.Param("imageID")
-	userID := c.MustGet("userID").(string)
-
-	// Validate ownership
-	var ownerID string
-	err := db.Pool.QueryRow(context.TODO(), "SELECT owner_user_id FROM vendor_profiles WHERE id=$1", vendorID).Scan(&ownerID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
-		return
-	}
-	if ownerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+	if !requireVendorRoleFor(c, vendorID, vendorRoleManager) {
 		return
 	}
 
 	// Get URL to delete from R2
 	var url string
-	err = db.Pool.QueryRow(context.TODO(), "SELECT image_url FROM vendor_gallery_images WHERE id=$1 AND vendor_id=$2", imageID, vendorID).Scan(&url)
+	err := db.Pool.QueryRow(context.TODO(), "SELECT image_url FROM vendor_gallery_images WHERE id=$1 AND vendor_id=$2", imageID, vendorID).Scan(&url)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
 		return
@@ -384,23 +595,13 @@ func (h *VendorHandler) DeleteGalleryImage(c *gin.Context) {
 // UploadPortfolioFile adds a PDF to the vendor's portfolio
 func (h *VendorHandler) UploadPortfolioFile(c *gin.Context) {
 	vendorID := c.Param("id")
-	userID := c.MustGet("userID").(string)
-
-	// Validate ownership
-	var ownerID string
-	err := db.Pool.QueryRow(context.TODO(), "SELECT owner_user_id FROM vendor_profiles WHERE id=$1", vendorID).Scan(&ownerID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
-		return
-	}
-	if ownerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+	if !requireVendorRoleFor(c, vendorID, vendorRoleManager) {
 		return
 	}
 
 	// Check limit (20)
 	var count int
-	err = db.Pool.QueryRow(context.TODO(), "SELECT COUNT(*) FROM vendor_portfolio_files WHERE vendor_id=$1", vendorID).Scan(&count)
+	err := db.Pool.QueryRow(context.TODO(), "SELECT COUNT(*) FROM vendor_portfolio_files WHERE vendor_id=$1", vendorID).Scan(&count)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
@@ -464,23 +665,13 @@ func (h *VendorHandler) UploadPortfolioFile(c *gin.Context) {
 func (h *VendorHandler) DeletePortfolioFile(c *gin.Context) {
 	vendorID := c.Param("id")
 	fileID := c.Param("fileID")
-	userID := c.MustGet("userID").(string)
-
-	// Validate ownership
-	var ownerID string
-	err := db.Pool.QueryRow(context.TODO(), "SELECT owner_user_id FROM vendor_profiles WHERE id=$1", vendorID).Scan(&ownerID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
-		return
-	}
-	if ownerID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+	if !requireVendorRoleFor(c, vendorID, vendorRoleManager) {
 		return
 	}
 
 	// Get URL to delete from R2
 	var url string
-	err = db.Pool.QueryRow(context.TODO(), "SELECT file_url FROM vendor_portfolio_files WHERE id=$1 AND vendor_id=$2", fileID, vendorID).Scan(&url)
+	err := db.Pool.QueryRow(context.TODO(), "SELECT file_url FROM vendor_portfolio_files WHERE id=$1 AND vendor_id=$2", fileID, vendorID).Scan(&url)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
 		return
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxSearchTerms      = 6
+	maxSearchPageSize   = 50
+	searchTrgmThreshold = 0.4 // low enough for "photografer" to find "photographer"
+
+	// ts_headline marks matches with these private-use characters; the text is
+	// HTML-escaped before they become <mark> tags
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+// GET /vendors/search?q=&category=&city=&limit=&offset=
+// Every search term must match a vendor, either through full-text search
+// (stemmed, prefix) or, for misspellings, trigram similarity on name, category
+// and city. Results are ranked and come with highlighted snippets, which are
+// escaped HTML with matches in <mark>.
+func (h *VendorHandler) SearchVendors(c *gin.Context) {
+	terms := searchTerms(c.Query("q"))
+	if len(terms) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	limit := defaultVendorPageSize
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(n, maxSearchPageSize)
+	}
+	offset := 0
+	if raw := c.Query("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+		offset = n
+	}
+
+	args := []interface{}{}
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	prefixTerms := make([]string, len(terms))
+	for i, t := range terms {
+		prefixTerms[i] = t + ":*"
+	}
+	anyTerm := addArg(strings.Join(prefixTerms, " | "))
+	fullQuery := addArg(strings.Join(terms, " "))
+	tsAny := "(to_tsquery('english', " + anyTerm + ") || to_tsquery('simple', " + anyTerm + "))"
+	marks := "StartSel=" + highlightStart + ", StopSel=" + highlightStop
+	nameOptions := addArg(marks + ", HighlightAll=true")
+	bioOptions := addArg(marks + ", MaxFragments=2, MaxWords=20, MinWords=5")
+
+	where := []string{"vp.status = 'verified'"}
+	for _, t := range terms {
+		prefix := addArg(t + ":*")
+		raw := addArg(t)
+		where = append(where, "(vp.search_vector @@ (to_tsquery('english', "+prefix+") || to_tsquery('simple', "+prefix+")) OR "+raw+" <% vp.search_text)")
+	}
+	if category := strings.TrimSpace(c.Query("category")); category != "" {
+		where = append(where, "lower(vp.category) = lower("+addArg(category)+")")
+	}
+	if city := strings.TrimSpace(c.Query("city")); city != "" {
+		where = append(where, "lower(vp.city) = lower("+addArg(city)+")")
+	}
+	whereSQL := strings.Join(where, " AND ")
+
+	query := `
+		SELECT 
+			vp.id, vp.business_name, vp.slug, vp.category, vp.city, vp.portfolio_image_url,
+			ts_headline('simple', vp.business_name, ` + tsAny + `, ` + nameOptions + `),
+			ts_headline('english', coalesce(vp.bio, ''), ` + tsAny + `, ` + bioOptions + `),
+			ts_rank_cd(vp.search_vector, ` + tsAny + `) * 2 + word_similarity(` + fullQuery + `, vp.search_text) AS rank,
+			COUNT(*) OVER () AS total
+		FROM vendor_profiles vp
+		WHERE ` + whereSQL + `
+		ORDER BY rank DESC, vp.shortlist_count DESC, vp.id
+		LIMIT ` + addArg(limit) + ` OFFSET ` + addArg(offset)
+
+	ctx := context.Background()
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search vendors"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// <% uses this threshold and stays index-assisted
+	if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %g", searchTrgmThreshold)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search vendors"})
+		return
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search vendors"})
+		return
+	}
+	defer rows.Close()
+
+	total := 0
+	results := []gin.H{}
+	for rows.Next() {
+		var id, name, slug, category, city, nameHighlight, snippet string
+		var portfolioImageURL *string
+		var rank float64
+		if err := rows.Scan(&id, &name, &slug, &category, &city, &portfolioImageURL, &nameHighlight, &snippet, &rank, &total); err != nil {
+			continue
+		}
+		results = append(results, gin.H{
+			"id":                  id,
+			"business_name":       name,
+			"slug":                slug,
+			"category":            category,
+			"city":                city,
+			"portfolio_image_url": portfolioImageURL,
+			"highlight": gin.H{
+				"business_name": highlightHTML(nameHighlight),
+				"bio":           highlightHTML(snippet),
+			},
+			"rank": rank,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// highlightHTML escapes vendor text from ts_headline and turns the match
+// markers into <mark> tags, so the result is safe to render as HTML
+func highlightHTML(s string) string {
+	s = html.EscapeString(s)
+	return strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>").Replace(s)
+}
+
+// searchTerms lower-cases the query and keeps only letters and digits, so the
+// terms are safe inside to_tsquery. Non-Latin scripts are kept as-is.
+func searchTerms(q string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := []string{}
+	seen := map[string]bool{}
+	for _, f := range fields {
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		terms = append(terms, f)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
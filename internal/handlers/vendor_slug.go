@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/slug"
+	"github.com/gin-gonic/gin"
+)
+
+const slugChangeCooldown = 7 * 24 * time.Hour
+
+type ChangeSlugRequest struct {
+	// Desired slug; empty regenerates it from the current business name and city
+	Slug string `json:"slug"`
+}
+
+// PUT /vendor/me/slug
+// Moves the vendor to a new slug. The old one is kept in vendor_slug_history
+// and keeps resolving, so existing links can be redirected. If the slug is
+// taken or reserved a short suffix is added. Only the owner can do this.
+func (h *VendorHandler) ChangeSlug(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	ctx := c.Request.Context()
+
+	var req ChangeSlugRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	vendorID, _, ok := requireVendorRole(c, vendorRoleOwner)
+	if !ok {
+		return
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change slug"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var currentSlug, businessName, city string
+	var changedAt *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT slug, business_name, city, slug_changed_at FROM vendor_profiles WHERE id = $1 FOR UPDATE
+	`, vendorID).Scan(&currentSlug, &businessName, &city, &changedAt)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor profile not found"})
+		return
+	}
+
+	base := slug.Make(businessName, city)
+	if strings.TrimSpace(req.Slug) != "" {
+		base = slug.Make(req.Slug)
+		if base == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Slug must contain letters or digits"})
+			return
+		}
+	}
+	if base == currentSlug {
+		c.JSON(http.StatusOK, gin.H{"message": "Slug unchanged", "slug": currentSlug})
+		return
+	}
+
+	if changedAt != nil && time.Since(*changedAt) < slugChangeCooldown {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "The slug can only be changed once every 7 days"})
+		return
+	}
+
+	// Each attempt runs in a savepoint so a collision doesn't abort the transaction.
+	// Another vendor's retired slug counts as taken; one of our own is reclaimed.
+	newSlug, err := slug.Allocate(base, "vendor", func(candidate string) error {
+		var takenInHistory bool
+		err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM vendor_slug_history WHERE slug = $1 AND vendor_id != $2)`, candidate, vendorID).Scan(&takenInHistory)
+		if err != nil {
+			return err
+		}
+		if takenInHistory {
+			return slug.ErrTaken
+		}
+
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = sp.Exec(ctx, `UPDATE vendor_profiles SET slug = $1, slug_changed_at = NOW(), updated_at = NOW() WHERE id = $2`, candidate, vendorID)
+		if err != nil {
+			sp.Rollback(ctx)
+			if strings.Contains(err.Error(), "vendor_profiles_slug_key") {
+				return slug.ErrTaken
+			}
+			return err
+		}
+		return sp.Commit(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, slug.ErrTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Slug is unavailable, please choose another"})
+			return
+		}
+		log.Printf("ERROR: Failed to change slug: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change slug"})
+		return
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM vendor_slug_history WHERE slug = $1 AND vendor_id = $2`, newSlug, vendorID)
+	if err == nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO vendor_slug_history (slug, vendor_id) VALUES ($1, $2)
+			ON CONFLICT (slug) DO UPDATE SET vendor_id = EXCLUDED.vendor_id, retired_at = NOW()
+		`, currentSlug, vendorID)
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to record slug history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change slug"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change slug"})
+		return
+	}
+
+	metadata := map[string]interface{}{"from": currentSlug, "to": newSlug}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('vendor', $1, 'slug_changed', $2, $3)`, vendorID, userID, metadata)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Slug changed",
+		"slug":          newSlug,
+		"previous_slug": currentSlug,
+	})
+}
+
+// resolveVendorSlug finds a verified vendor by its current or a retired slug
+// and returns its ID and current slug.
+func resolveVendorSlug(ctx context.Context, slug string) (string, string, error) {
+	var vendorID, canonical string
+	err := db.Pool.QueryRow(ctx, `
+		SELECT id, slug FROM vendor_profiles
+		WHERE status = 'verified'
+		  AND (slug = $1 OR id = (SELECT vendor_id FROM vendor_slug_history WHERE slug = $1))
+		ORDER BY slug = $1 DESC
+		LIMIT 1
+	`, slug).Scan(&vendorID, &canonical)
+	return vendorID, canonical, err
+}
@@ -0,0 +1,476 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/auth"
+	"github.com/bventy/backend/internal/db"
+	"github.com/bventy/backend/internal/mailer"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Vendor team roles. Responders handle the quote inbox; managers also run the
+// profile, media, packages, calendar and review replies and can bring in
+// responders; the owner additionally controls the slug and the whole team.
+const (
+	vendorRoleResponder = "responder"
+	vendorRoleManager   = "manager"
+	vendorRoleOwner     = "owner"
+)
+
+var vendorRoleRank = map[string]int{
+	vendorRoleResponder: 1,
+	vendorRoleManager:   2,
+	vendorRoleOwner:     3,
+}
+
+const (
+	maxVendorTeamSize = 20 // members plus pending invitations
+	vendorInviteTTL   = 7 * 24 * time.Hour
+)
+
+type VendorTeamMember struct {
+	UserID   string    `json:"user_id"`
+	FullName string    `json:"full_name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type VendorTeamInvitation struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type InviteTeamMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"` // manager or responder
+}
+
+type AcceptTeamInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type UpdateTeamMemberRequest struct {
+	Role string `json:"role" binding:"required"` // manager or responder
+}
+
+// vendorMembership returns the vendor the user works for and their role on
+// its team. pgx.ErrNoRows means the user is on no vendor team.
+func vendorMembership(ctx context.Context, userID string) (string, string, error) {
+	var vendorID, role string
+	err := db.Pool.QueryRow(ctx, `SELECT vendor_id, role FROM vendor_team_members WHERE user_id = $1`, userID).Scan(&vendorID, &role)
+	return vendorID, role, err
+}
+
+// requireVendorRole finds the caller's vendor and checks that their team role
+// is at least minRole. It writes the error response itself when not.
+func requireVendorRole(c *gin.Context, minRole string) (string, string, bool) {
+	vendorID, role, err := vendorMembership(c.Request.Context(), c.MustGet("userID").(string))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor profile not found"})
+		return "", "", false
+	}
+	if vendorRoleRank[role] < vendorRoleRank[minRole] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role on the vendor team does not allow this"})
+		return "", "", false
+	}
+	return vendorID, role, true
+}
+
+// requireVendorRoleFor is requireVendorRole for routes that name the vendor
+// by ID.
+func requireVendorRoleFor(c *gin.Context, vendorID, minRole string) bool {
+	var role *string
+	err := db.Pool.QueryRow(c.Request.Context(), `
+		SELECT (SELECT role FROM vendor_team_members WHERE vendor_id = vp.id AND user_id = $2)
+		FROM vendor_profiles vp WHERE vp.id = $1
+	`, vendorID, c.MustGet("userID").(string)).Scan(&role)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return false
+	}
+	if role == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not on this vendor's team"})
+		return false
+	}
+	if vendorRoleRank[*role] < vendorRoleRank[minRole] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role on the vendor team does not allow this"})
+		return false
+	}
+	return true
+}
+
+// GET /vendor/me/team
+// Members are visible to the whole team; pending invitations to managers and the owner.
+func (h *VendorHandler) GetMyTeam(c *gin.Context) {
+	vendorID, role, ok := requireVendorRole(c, vendorRoleResponder)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	rows, err := db.Pool.Query(ctx, `
+		SELECT tm.user_id, u.full_name, u.email, tm.role, tm.created_at
+		FROM vendor_team_members tm JOIN users u ON u.id = tm.user_id
+		WHERE tm.vendor_id = $1
+		ORDER BY (tm.role = 'owner') DESC, (tm.role = 'manager') DESC, tm.created_at
+	`, vendorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team"})
+		return
+	}
+	defer rows.Close()
+
+	members := []VendorTeamMember{}
+	for rows.Next() {
+		var m VendorTeamMember
+		if err := rows.Scan(&m.UserID, &m.FullName, &m.Email, &m.Role, &m.JoinedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team"})
+			return
+		}
+		members = append(members, m)
+	}
+
+	response := gin.H{"role": role, "members": members}
+
+	if vendorRoleRank[role] >= vendorRoleRank[vendorRoleManager] {
+		invitations, err := loadPendingTeamInvitations(ctx, vendorID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+			return
+		}
+		response["invitations"] = invitations
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// POST /vendor/me/team/invitations
+// Emails an invitation link. Managers can invite responders; the owner can
+// invite managers too. A new invitation for the same address replaces the old one.
+func (h *VendorHandler) InviteTeamMember(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	var req InviteTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	email := strings.TrimSpace(req.Email)
+	if req.Role != vendorRoleManager && req.Role != vendorRoleResponder {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be manager or responder"})
+		return
+	}
+
+	vendorID, role, ok := requireVendorRole(c, vendorRoleManager)
+	if !ok {
+		return
+	}
+	if vendorRoleRank[req.Role] >= vendorRoleRank[role] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can invite managers"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var businessName, inviterName string
+	var isMember bool
+	var teamSize int
+	err := db.Pool.QueryRow(ctx, `
+		SELECT vp.business_name,
+		       (SELECT full_name FROM users WHERE id = $2),
+		       EXISTS (SELECT 1 FROM vendor_team_members tm JOIN users u ON u.id = tm.user_id
+		               WHERE tm.vendor_id = vp.id AND lower(u.email) = lower($3)),
+		       (SELECT COUNT(*) FROM vendor_team_members WHERE vendor_id = vp.id) +
+		       (SELECT COUNT(*) FROM vendor_team_invitations
+		        WHERE vendor_id = vp.id AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		          AND lower(invited_email) != lower($3))
+		FROM vendor_profiles vp WHERE vp.id = $1
+	`, vendorID, userID, email).Scan(&businessName, &inviterName, &isMember, &teamSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+	if isMember {
+		c.JSON(http.StatusConflict, gin.H{"error": "This person is already on your team"})
+		return
+	}
+	if teamSize >= maxVendorTeamSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A vendor team can have at most 20 members and pending invitations"})
+		return
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE vendor_team_invitations SET revoked_at = NOW()
+		WHERE vendor_id = $1 AND lower(invited_email) = lower($2) AND accepted_at IS NULL AND revoked_at IS NULL
+	`, vendorID, email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	var invitationID string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO vendor_team_invitations (vendor_id, invited_email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, vendorID, email, req.Role, tokenHash, userID, time.Now().Add(vendorInviteTTL)).Scan(&invitationID)
+	if err != nil {
+		log.Printf("ERROR: Failed to create vendor team invitation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	metadata := map[string]interface{}{"email": email, "role": req.Role}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('vendor', $1, 'team_invitation_sent', $2, $3)`, vendorID, userID, metadata)
+
+	link := h.Config.FrontendURL + "/vendor/team/accept?token=" + url.QueryEscape(token)
+	mailer.SendAsync(h.Mailer, mailer.Message{
+		To:      email,
+		Subject: "You're invited to join " + businessName + " on Bventy",
+		Body: inviterName + " invited you to join the " + businessName + " team on Bventy as a " + req.Role + ".\n\n" +
+			"Sign in or create an account with this email address, then accept the invitation:\n" + link + "\n\n" +
+			"The link expires in 7 days. If you weren't expecting this, you can ignore this email.",
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Invitation sent", "id": invitationID})
+}
+
+// DELETE /vendor/me/team/invitations/:id
+func (h *VendorHandler) RevokeTeamInvitation(c *gin.Context) {
+	vendorID, role, ok := requireVendorRole(c, vendorRoleManager)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	// Managers can only withdraw invitations for roles below their own
+	tag, err := db.Pool.Exec(ctx, `
+		UPDATE vendor_team_invitations SET revoked_at = NOW()
+		WHERE id = $1 AND vendor_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+		  AND (role = 'responder' OR $3 = 'owner')
+	`, c.Param("id"), vendorID, role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// POST /vendor/team/accept
+// The signed-in user's email must match the invited address.
+func (h *VendorHandler) AcceptTeamInvitation(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+
+	var req AcceptTeamInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var invitationID, vendorID, invitedEmail, role, businessName string
+	var invitedBy *string
+	err = tx.QueryRow(ctx, `
+		SELECT i.id, i.vendor_id, i.invited_email, i.role, i.invited_by::text, vp.business_name
+		FROM vendor_team_invitations i JOIN vendor_profiles vp ON vp.id = i.vendor_id
+		WHERE i.token_hash = $1 AND i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > NOW()
+		  AND vp.status != 'deleted'
+		FOR UPDATE OF i
+	`, auth.HashToken(req.Token)).Scan(&invitationID, &vendorID, &invitedEmail, &role, &invitedBy, &businessName)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invitation is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate invitation"})
+		return
+	}
+
+	var email string
+	if err := tx.QueryRow(ctx, `SELECT email FROM users WHERE id = $1`, userID).Scan(&email); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !strings.EqualFold(email, invitedEmail) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This invitation was sent to a different email address"})
+		return
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO vendor_team_members (vendor_id, user_id, role, invited_by) VALUES ($1, $2, $3, $4)
+	`, vendorID, userID, role, invitedBy)
+	if err != nil {
+		if strings.Contains(err.Error(), "unique constraint") {
+			c.JSON(http.StatusConflict, gin.H{"error": "You are already on a vendor team. Leave it before joining another."})
+			return
+		}
+		log.Printf("ERROR: Failed to add vendor team member: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE vendor_team_invitations SET accepted_at = NOW(), accepted_by = $2 WHERE id = $1`, invitationID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	metadata := map[string]interface{}{"role": role}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('vendor', $1, 'team_member_joined', $2, $3)`, vendorID, userID, metadata)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "You joined " + businessName,
+		"vendor_id":     vendorID,
+		"business_name": businessName,
+		"role":          role,
+	})
+}
+
+// PATCH /vendor/me/team/:userID
+// Owner only. Ownership itself cannot be handed over here.
+func (h *VendorHandler) UpdateTeamMember(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	memberID := c.Param("userID")
+
+	var req UpdateTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Role != vendorRoleManager && req.Role != vendorRoleResponder {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be manager or responder"})
+		return
+	}
+
+	vendorID, _, ok := requireVendorRole(c, vendorRoleOwner)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	tag, err := db.Pool.Exec(ctx, `
+		UPDATE vendor_team_members SET role = $3, updated_at = NOW()
+		WHERE vendor_id = $1 AND user_id = $2 AND role != 'owner'
+	`, vendorID, memberID, req.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team member"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+		return
+	}
+
+	metadata := map[string]interface{}{"member_user_id": memberID, "role": req.Role}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('vendor', $1, 'team_role_changed', $2, $3)`, vendorID, userID, metadata)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team member updated"})
+}
+
+// DELETE /vendor/me/team/:userID
+// Removes a member with a lower role than the caller's, or the caller
+// themselves (leaving the team). The owner cannot leave.
+func (h *VendorHandler) RemoveTeamMember(c *gin.Context) {
+	userID := c.MustGet("userID").(string)
+	memberID := c.Param("userID")
+
+	vendorID, role, ok := requireVendorRole(c, vendorRoleResponder)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	var memberRole string
+	err := db.Pool.QueryRow(ctx, `SELECT role FROM vendor_team_members WHERE vendor_id = $1 AND user_id = $2`, vendorID, memberID).Scan(&memberRole)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+		return
+	}
+	if memberRole == vendorRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "The owner cannot be removed from the team"})
+		return
+	}
+	if memberID != userID && vendorRoleRank[memberRole] >= vendorRoleRank[role] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role on the vendor team does not allow this"})
+		return
+	}
+
+	_, err = db.Pool.Exec(ctx, `DELETE FROM vendor_team_members WHERE vendor_id = $1 AND user_id = $2`, vendorID, memberID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove team member"})
+		return
+	}
+
+	metadata := map[string]interface{}{"member_user_id": memberID, "role": memberRole}
+	_, _ = db.Pool.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id, metadata) VALUES ('vendor', $1, 'team_member_removed', $2, $3)`, vendorID, userID, metadata)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team member removed"})
+}
+
+func loadPendingTeamInvitations(ctx context.Context, vendorID string) ([]VendorTeamInvitation, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id, invited_email, role, created_at, expires_at FROM vendor_team_invitations
+		WHERE vendor_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`, vendorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []VendorTeamInvitation{}
+	for rows.Next() {
+		var i VendorTeamInvitation
+		if err := rows.Scan(&i.ID, &i.Email, &i.Role, &i.CreatedAt, &i.ExpiresAt); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, i)
+	}
+	return invitations, rows.Err()
+}
@@ -0,0 +1,118 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bventy/backend/internal/config"
+)
+
+// Message is a plain-text transactional email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional email (password resets, verification links, notices)
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SendAsync delivers msg in the background so a request never waits on the
+// mail server. Failures are only logged.
+func SendAsync(m Mailer, msg Message) {
+	go func() {
+		if err := m.Send(context.Background(), msg); err != nil {
+			log.Printf("ERROR: Failed to send %q email: %v", msg.Subject, err)
+		}
+	}()
+}
+
+// New picks the implementation from MAIL_DRIVER. Anything other than "smtp"
+// falls back to the log mailer so local development never sends real email.
+func New(cfg *config.Config) Mailer {
+	if cfg.MailDriver == "smtp" {
+		return &SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}
+	}
+	return &LogMailer{OutboxDir: cfg.MailOutboxDir}
+}
+
+// SMTPMailer sends mail through an SMTP relay
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%s", m.Host, m.Port)
+	if err := smtp.SendMail(addr, auth, m.From, []string{msg.To}, buildMessage(m.From, msg)); err != nil {
+		return fmt.Errorf("failed to send mail via SMTP: %w", err)
+	}
+	return nil
+}
+
+// LogMailer prints messages to the server log and, if OutboxDir is set,
+// writes each one to a .eml file there for inspection during development.
+type LogMailer struct {
+	OutboxDir string
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("📧 Mail to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+
+	if m.OutboxDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.OutboxDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create outbox dir: %w", err)
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), sanitizeFilename(msg.To))
+	if err := os.WriteFile(filepath.Join(m.OutboxDir, name), buildMessage("dev@localhost", msg), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail to outbox: %w", err)
+	}
+	return nil
+}
+
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
+
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -21,19 +21,109 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := auth.ValidateToken(tokenString, cfg)
+		if auth.LooksLikeAPIKey(tokenString) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "API keys are not accepted on this endpoint"})
+			c.Abort()
+			return
+		}
+
+		authenticateJWT(c, tokenString, cfg)
+	}
+}
+
+// AuthOrAPIKey accepts either a user JWT or an API key, sent as
+// "Authorization: Bearer bv_..." or "X-API-Key: bv_...". Routes behind it
+// should declare the scope API keys need with RequireScope.
+func AuthOrAPIKey(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			tokenString = key
+		}
+		if tokenString == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Abort()
+			return
+		}
+
+		if !auth.LooksLikeAPIKey(tokenString) {
+			authenticateJWT(c, tokenString, cfg)
+			return
+		}
+
+		principal, err := auth.ValidateAPIKey(c.Request.Context(), tokenString)
+		if err == auth.ErrAccountSuspended {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+			c.Abort()
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
 			c.Abort()
 			return
 		}
 
-		c.Set("userID", claims.UserID)
-		c.Set("role", claims.Role)
+		auth.TouchAPIKey(principal.KeyID, c.ClientIP())
+
+		c.Set("userID", principal.UserID)
+		c.Set("role", principal.Role)
+		c.Set("apiKey", principal)
 		c.Next()
 	}
 }
 
+// RequireScope limits API-key requests to keys granted scope. Requests
+// authenticated with a user JWT are not restricted by scopes.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get("apiKey")
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if principal, _ := value.(*auth.APIKeyPrincipal); principal != nil && principal.HasScope(scope) {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key is missing scope '" + scope + "'"})
+		c.Abort()
+	}
+}
+
+// authenticateJWT validates an access token and continues the chain as its user
+func authenticateJWT(c *gin.Context, tokenString string, cfg *config.Config) {
+	claims, err := auth.ValidateToken(tokenString, cfg)
+	if err == auth.ErrAccountSuspended {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is suspended"})
+		c.Abort()
+		return
+	}
+	if err == auth.ErrTokenOutdated {
+		// Role or credentials changed since issue; the client should refresh
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token outdated", "code": "token_outdated"})
+		c.Abort()
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+
+	c.Set("userID", claims.UserID)
+	c.Set("role", claims.Role)
+	c.Set("sessionID", claims.SessionID)
+	auth.TouchSession(claims.SessionID)
+
+	if claims.ImpersonatorID != "" {
+		guardImpersonation(c, claims)
+		return
+	}
+	c.Next()
+}
+
 // Role Hierarchy: super_admin > admin > staff > user
 func getRoleLevel(role string) int {
 	switch role {
@@ -50,6 +140,11 @@ func getRoleLevel(role string) int {
 	}
 }
 
+// RoleAtLeast reports whether role sits at or above minRole in the hierarchy
+func RoleAtLeast(role, minRole string) bool {
+	return getRoleLevel(role) >= getRoleLevel(minRole)
+}
+
 func RequireRole(minRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("role")
@@ -70,6 +165,25 @@ func RequireRole(minRole string) gin.HandlerFunc {
 	}
 }
 
+// HasPermission reports whether the user holds the permission, either through a
+// direct grant or through their role's defaults. super_admin holds everything.
+func HasPermission(ctx context.Context, userID, role, permission string) bool {
+	if role == "super_admin" {
+		return true
+	}
+
+	query := `
+		SELECT 1 FROM permissions p
+		WHERE p.code = $2 AND (
+			EXISTS (SELECT 1 FROM user_permissions up WHERE up.permission_id = p.id AND up.user_id = $1)
+			OR EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.permission_id = p.id AND rp.role = $3)
+		)
+	`
+	var existsFlag int
+	err := db.Pool.QueryRow(ctx, query, userID, permission, role).Scan(&existsFlag)
+	return err == nil
+}
+
 func RequirePermission(requiredPermission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := c.Get("userID")
@@ -79,28 +193,72 @@ func RequirePermission(requiredPermission string) gin.HandlerFunc {
 			return
 		}
 
-		// Check database for permission
-		query := `
-			SELECT 1 FROM user_permissions up
-			JOIN permissions p ON up.permission_id = p.id
-			WHERE up.user_id = $1 AND p.code = $2
-		`
-		var existsFlag int
-		err := db.Pool.QueryRow(context.Background(), query, userID, requiredPermission).Scan(&existsFlag)
+		userRole, _ := c.Get("role")
+		roleStr, _ := userRole.(string)
+
+		if HasPermission(context.Background(), userID.(string), roleStr, requiredPermission) {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Missing permission '" + requiredPermission + "'"})
+		c.Abort()
+	}
+}
 
-		if err == nil {
+// RequireVerifiedEmail blocks users who have not confirmed their email address.
+// It is a no-op when REQUIRE_EMAIL_VERIFICATION is disabled.
+func RequireVerifiedEmail(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.RequireEmailVerification {
+			c.Next()
+			return
+		}
+
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		var verified bool
+		err := db.Pool.QueryRow(context.Background(), "SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1", userID).Scan(&verified)
+		if err == nil && verified {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address first", "code": "email_unverified"})
+		c.Abort()
+	}
+}
+
+// RequireTwoFactor blocks admin-level accounts that have not enabled 2FA
+// when REQUIRE_2FA_FOR_ADMINS is on. Lower roles pass through.
+func RequireTwoFactor(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Require2FAForAdmins {
 			c.Next()
 			return
 		}
 
-		// Allow super_admin to bypass permission check
 		userRole, _ := c.Get("role")
-		if userRole == "super_admin" {
+		roleStr, _ := userRole.(string)
+		if !RoleAtLeast(roleStr, "admin") {
 			c.Next()
 			return
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Missing permission '" + requiredPermission + "'"})
+		userID, _ := c.Get("userID")
+		var enabled bool
+		err := db.Pool.QueryRow(context.Background(), "SELECT totp_enabled_at IS NOT NULL FROM users WHERE id = $1", userID).Scan(&enabled)
+		if err == nil && enabled {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication must be enabled for this account", "code": "two_factor_required"})
 		c.Abort()
 	}
 }
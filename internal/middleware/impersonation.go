@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bventy/backend/internal/auth"
+	"github.com/bventy/backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// Requests an impersonator may still make with a mutating method
+var impersonationAllowedWrites = map[string]bool{
+	"/auth/impersonation/stop": true,
+}
+
+// guardImpersonation runs the rest of the chain for a request made with an
+// impersonation token: it marks the response, refuses anything that would
+// change state on the user's behalf, and records the request in the audit log.
+func guardImpersonation(c *gin.Context, claims *auth.Claims) {
+	c.Set("impersonatorID", claims.ImpersonatorID)
+	c.Header("X-Impersonated-By", claims.ImpersonatorID)
+
+	blocked := false
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		blocked = !impersonationAllowedWrites[c.Request.URL.Path]
+	}
+
+	if blocked {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This action is not allowed while impersonating", "code": "impersonation_read_only"})
+		c.Abort()
+	} else {
+		c.Next()
+	}
+
+	_, _ = db.Pool.Exec(context.Background(), `
+		INSERT INTO impersonation_audit_log (session_id, impersonator_id, impersonated_user_id, method, path, status, blocked, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, claims.SessionID, claims.ImpersonatorID, claims.UserID, c.Request.Method, c.Request.URL.RequestURI(), c.Writer.Status(), blocked, c.ClientIP())
+}
+
+// BlockWhenImpersonating refuses read endpoints that nonetheless have side
+// effects for the user (e.g. unlocking vendor contact details) or hand out
+// everything we hold about them (the data export).
+func BlockWhenImpersonating() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("impersonatorID"); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This action is not allowed while impersonating", "code": "impersonation_read_only"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,200 @@
+// Package oidc implements the relying-party side of OpenID Connect login:
+// discovery, authorization-code + PKCE, token exchange and ID token checks.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bventy/backend/internal/config"
+)
+
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// Identity is what we take from a verified ID token
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Client talks to a single provider. Discovery and keys are fetched lazily and cached.
+type Client struct {
+	provider config.OIDCProvider
+	http     *http.Client
+
+	mu            sync.Mutex
+	discovery     *discoveryDocument
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = map[string]*Client{}
+)
+
+// ClientFor returns the shared client for a provider
+func ClientFor(p config.OIDCProvider) *Client {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	key := p.Name + "|" + p.Issuer + "|" + p.ClientID
+	if c, ok := clients[key]; ok {
+		return c
+	}
+	c := &Client{provider: p, http: &http.Client{Timeout: 10 * time.Second}}
+	clients[key] = c
+	return c
+}
+
+// RandomToken returns a URL-safe random string for state, nonce and PKCE verifiers
+func RandomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge derives the S256 PKCE challenge for a verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL builds the URL the browser is sent to
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	doc, err := c.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", c.provider.ClientID)
+	q.Set("redirect_uri", c.provider.RedirectURL)
+	q.Set("scope", strings.Join(c.provider.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", CodeChallenge(codeVerifier))
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return doc.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange trades an authorization code for the provider's raw ID token
+func (c *Client) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	doc, err := c.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.provider.RedirectURL)
+	form.Set("client_id", c.provider.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if c.provider.ClientSecret != "" {
+		form.Set("client_secret", c.provider.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tokenResp struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", err
+	}
+	if tokenResp.IDToken == "" {
+		return "", errors.New("token response did not include an id_token")
+	}
+	return tokenResp.IDToken, nil
+}
+
+func (c *Client) getDiscovery(ctx context.Context) (*discoveryDocument, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	var doc discoveryDocument
+	if err := c.getJSON(ctx, c.provider.Issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("oidc discovery for %s: %w", c.provider.Name, err)
+	}
+	if doc.Issuer != c.provider.Issuer {
+		return nil, fmt.Errorf("oidc discovery for %s: issuer mismatch (%q)", c.provider.Name, doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc discovery for %s: incomplete document", c.provider.Name)
+	}
+
+	c.discovery = &doc
+	return c.discovery, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
@@ -14,18 +14,25 @@ func RegisterRoutes(r *gin.Engine) {
 	// Handlers
 	authHandler := handlers.NewAuthHandler(cfg)
 	vendorHandler := handlers.NewVendorHandler(cfg)
-	adminHandler := handlers.NewAdminHandler()
+	adminHandler := handlers.NewAdminHandler(cfg)
 	userHandler := handlers.NewUserHandler(cfg)
 	groupHandler := handlers.NewGroupHandler()
 	eventHandler := handlers.NewEventHandler()
 	mediaHandler := handlers.NewMediaHandler(cfg)
-	quotesHandler := handlers.NewQuotesHandler()
+	quotesHandler := handlers.NewQuotesHandler(cfg)
 	trackHandler := handlers.NewTrackHandler()
+	apiKeyHandler := handlers.NewAPIKeyHandler()
+	searchHandler := handlers.NewSearchHandler()
 
 	// Public Routes
 	r.GET("/health", handlers.HealthCheck)
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
 	r.GET("/vendors", vendorHandler.ListVerifiedVendors)
+	r.GET("/vendors/search", vendorHandler.SearchVendors)
+	r.GET("/search/suggest", searchHandler.Suggest)
 	r.GET("/vendors/slug/:slug", vendorHandler.GetVendorBySlug)
+	r.GET("/vendors/slug/:slug/reviews", quotesHandler.ListVendorReviews)
+	r.GET("/vendors/slug/:slug/availability", vendorHandler.GetVendorAvailability)
 
 	// Media Upload (Protected? or Public? usually protected)
 	// User didn't specify, but let's make it protected to prevent abuse.
@@ -35,6 +42,52 @@ func RegisterRoutes(r *gin.Engine) {
 	{
 		authGroup.POST("/signup", authHandler.Signup)
 		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/login/2fa", authHandler.LoginTwoFactor)
+		authGroup.POST("/refresh", authHandler.Refresh)
+		authGroup.POST("/logout", authHandler.Logout)
+		authGroup.POST("/forgot-password", authHandler.ForgotPassword)
+		authGroup.POST("/reset-password", authHandler.ResetPassword)
+		authGroup.POST("/verify-email", authHandler.VerifyEmail)
+		authGroup.POST("/confirm-email-change", authHandler.ConfirmEmailChange)
+
+		// OpenID Connect (social login)
+		authGroup.GET("/oidc/providers", authHandler.ListOIDCProviders)
+		authGroup.POST("/oidc/:provider/start", authHandler.StartOIDCLogin)
+		authGroup.POST("/oidc/:provider/callback", authHandler.OIDCCallback)
+	}
+
+	// Integration Routes (user JWT or a scoped API key)
+	integration := r.Group("/")
+	integration.Use(middleware.AuthOrAPIKey(cfg))
+	{
+		// Vendor Profile
+		integration.GET("/vendor/me", middleware.RequireScope("vendor:read"), vendorHandler.GetMyProfile)
+		integration.PUT("/vendor/me", middleware.RequireScope("vendor:write"), vendorHandler.UpdateVendor)
+		integration.PUT("/vendor/me/slug", middleware.RequireScope("vendor:write"), vendorHandler.ChangeSlug)
+
+		// Vendor Service Packages
+		integration.GET("/vendor/me/packages", middleware.RequireScope("vendor:read"), vendorHandler.ListMyPackages)
+		integration.POST("/vendor/me/packages", middleware.RequireScope("vendor:write"), vendorHandler.CreatePackage)
+		integration.PUT("/vendor/me/packages/:id", middleware.RequireScope("vendor:write"), vendorHandler.UpdatePackage)
+		integration.DELETE("/vendor/me/packages/:id", middleware.RequireScope("vendor:write"), vendorHandler.DeletePackage)
+
+		// Vendor Service Areas
+		integration.GET("/vendor/me/service-areas", middleware.RequireScope("vendor:read"), vendorHandler.GetMyServiceAreas)
+		integration.PUT("/vendor/me/service-areas", middleware.RequireScope("vendor:write"), vendorHandler.UpdateMyServiceAreas)
+
+		// Vendor Availability Calendar
+		integration.GET("/vendor/me/availability", middleware.RequireScope("vendor:read"), vendorHandler.GetMyAvailability)
+		integration.PUT("/vendor/me/availability", middleware.RequireScope("vendor:write"), vendorHandler.UpdateMyAvailability)
+
+		// Vendor Gallery & Portfolio
+		integration.POST("/vendors/:id/gallery", middleware.RequireScope("vendor:write"), vendorHandler.UploadGalleryImage)
+		integration.DELETE("/vendors/:id/gallery/:imageID", middleware.RequireScope("vendor:write"), vendorHandler.DeleteGalleryImage)
+		integration.POST("/vendors/:id/portfolio", middleware.RequireScope("vendor:write"), vendorHandler.UploadPortfolioFile)
+		integration.DELETE("/vendors/:id/portfolio/:fileID", middleware.RequireScope("vendor:write"), vendorHandler.DeletePortfolioFile)
+
+		// Vendor Quote Inbox
+		integration.GET("/quotes/vendor", middleware.RequireScope("quotes:read"), quotesHandler.GetVendorQuotes)
+		integration.PATCH("/quotes/respond/:id", middleware.RequireScope("quotes:respond"), quotesHandler.RespondToQuote)
 	}
 
 	// Protected Routes (Require Auth)
@@ -44,6 +97,33 @@ func RegisterRoutes(r *gin.Engine) {
 		// User & Dashboard
 		protected.GET("/me", userHandler.GetMe)
 		protected.PUT("/me", userHandler.UpdateMe)
+		protected.PUT("/me/password", authHandler.ChangePassword)
+		protected.POST("/me/email", authHandler.RequestEmailChange)
+		protected.GET("/me/export", middleware.BlockWhenImpersonating(), userHandler.ExportMyData)
+		protected.DELETE("/me", userHandler.RequestAccountDeletion)
+		protected.POST("/me/deletion/cancel", userHandler.CancelAccountDeletion)
+		protected.POST("/auth/resend-verification", authHandler.ResendVerification)
+		protected.POST("/auth/impersonation/stop", authHandler.StopImpersonation)
+
+		// Linked Identities
+		protected.GET("/me/identities", authHandler.ListIdentities)
+		protected.DELETE("/me/identities/:provider", authHandler.UnlinkIdentity)
+
+		// API Keys (managed with a user session only)
+		protected.GET("/me/api-keys", apiKeyHandler.ListAPIKeys)
+		protected.POST("/me/api-keys", apiKeyHandler.CreateAPIKey)
+		protected.DELETE("/me/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+		// Sessions & Devices
+		protected.GET("/me/sessions", authHandler.ListSessions)
+		protected.DELETE("/me/sessions", authHandler.RevokeAllMySessions)
+		protected.DELETE("/me/sessions/:id", authHandler.RevokeMySession)
+
+		// Two-Factor Authentication
+		protected.POST("/me/2fa/enroll", authHandler.EnrollTwoFactor)
+		protected.POST("/me/2fa/confirm", authHandler.ConfirmTwoFactor)
+		protected.POST("/me/2fa/disable", authHandler.DisableTwoFactor)
+		protected.POST("/me/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
 
 		// Profile Image
 		protected.POST("/users/profile-image", userHandler.UploadProfileImage)
@@ -55,15 +135,21 @@ func RegisterRoutes(r *gin.Engine) {
 		protected.POST("/track/activity", trackHandler.TrackActivity)
 
 		// Vendor Onboarding & Management
-		protected.POST("/vendor/onboard", vendorHandler.OnboardVendor)
-		protected.GET("/vendor/me", vendorHandler.GetMyProfile)
-		protected.PUT("/vendor/me", vendorHandler.UpdateVendor)
-
-		// Vendor Gallery & Portfolio
-		protected.POST("/vendors/:id/gallery", vendorHandler.UploadGalleryImage)
-		protected.DELETE("/vendors/:id/gallery/:imageID", vendorHandler.DeleteGalleryImage)
-		protected.POST("/vendors/:id/portfolio", vendorHandler.UploadPortfolioFile)
-		protected.DELETE("/vendors/:id/portfolio/:fileID", vendorHandler.DeletePortfolioFile)
+		protected.POST("/vendor/onboard", middleware.RequireVerifiedEmail(cfg), vendorHandler.OnboardVendor)
+
+		// Vendor Teams (managed with a user session only)
+		protected.GET("/vendor/me/team", vendorHandler.GetMyTeam)
+		protected.POST("/vendor/me/team/invitations", vendorHandler.InviteTeamMember)
+		protected.DELETE("/vendor/me/team/invitations/:id", vendorHandler.RevokeTeamInvitation)
+		protected.PATCH("/vendor/me/team/:userID", vendorHandler.UpdateTeamMember)
+		protected.DELETE("/vendor/me/team/:userID", vendorHandler.RemoveTeamMember)
+		protected.POST("/vendor/team/accept", vendorHandler.AcceptTeamInvitation)
+
+		// Vendor Verification (owner only, never via API keys)
+		protected.GET("/vendor/me/kyc", vendorHandler.GetMyKYC)
+		protected.POST("/vendor/me/kyc/documents", vendorHandler.UploadKYCDocument)
+		protected.DELETE("/vendor/me/kyc/documents/:id", vendorHandler.RemoveKYCDocument)
+		protected.POST("/vendor/me/kyc/submit", vendorHandler.SubmitKYC)
 
 		// Groups
 		protected.POST("/groups", groupHandler.CreateGroup)
@@ -75,55 +161,76 @@ func RegisterRoutes(r *gin.Engine) {
 		protected.GET("/events/:id", eventHandler.GetEventById)
 		protected.POST("/events/:id/shortlist/:vendorID", eventHandler.ShortlistVendor)
 		protected.GET("/events/:id/shortlist", eventHandler.GetShortlistedVendors)
+		protected.GET("/events/:id/vendor-suggestions", eventHandler.SuggestVendors)
 
 		// Quotes
-		protected.POST("/quotes/request", quotesHandler.CreateQuoteRequest)
-		protected.GET("/quotes/vendor", quotesHandler.GetVendorQuotes)
+		protected.POST("/quotes/request", middleware.RequireVerifiedEmail(cfg), quotesHandler.CreateQuoteRequest)
 		protected.GET("/quotes/organizer", quotesHandler.GetOrganizerQuotes)
-		protected.PATCH("/quotes/respond/:id", quotesHandler.RespondToQuote)
 		protected.PATCH("/quotes/accept/:id", quotesHandler.AcceptQuote)
 		protected.PATCH("/quotes/reject/:id", quotesHandler.RejectQuote)
 		protected.PATCH("/quotes/revision/:id", quotesHandler.RequestRevision)
-		protected.GET("/quotes/:id/contact", quotesHandler.GetQuoteContact)
+		protected.GET("/quotes/:id/contact", middleware.BlockWhenImpersonating(), quotesHandler.GetQuoteContact)
 
-		// Admin Routes (Admin & Super Admin)
+		// Reviews
+		protected.POST("/quotes/:id/review", quotesHandler.CreateReview)
+		protected.PUT("/reviews/:id/reply", quotesHandler.ReplyToReview)
+		protected.POST("/reviews/:id/report", quotesHandler.ReportReview)
+
+		// Admin Routes (Staff and above; each route gated by a named permission)
 		adminRoutes := protected.Group("/admin")
-		adminRoutes.Use(middleware.AdminOnly())
+		adminRoutes.Use(middleware.RequireRole("staff"), middleware.RequireTwoFactor(cfg))
 		{
 			// Dashboard Stats (Legacy)
-			adminRoutes.GET("/stats", adminHandler.GetStats)
+			adminRoutes.GET("/stats", middleware.RequirePermission("metrics.view"), adminHandler.GetStats)
 
 			// Analytics Layer
 			adminMetricsHandler := handlers.NewAdminMetricsHandler()
-			adminRoutes.GET("/metrics/overview", adminMetricsHandler.GetAdminMetricsOverview)
-			adminRoutes.GET("/metrics/growth", adminMetricsHandler.GetAdminMetricsGrowth)
-			adminRoutes.GET("/metrics/events", adminMetricsHandler.GetAdminMetricsEvents)
-			adminRoutes.GET("/metrics/vendors", adminMetricsHandler.GetAdminMetricsVendors)
-			adminRoutes.GET("/metrics/marketplace", adminMetricsHandler.GetAdminMetricsMarketplace)
+			metricsRoutes := adminRoutes.Group("/metrics", middleware.RequirePermission("metrics.view"))
+			metricsRoutes.GET("/overview", adminMetricsHandler.GetAdminMetricsOverview)
+			metricsRoutes.GET("/growth", adminMetricsHandler.GetAdminMetricsGrowth)
+			metricsRoutes.GET("/events", adminMetricsHandler.GetAdminMetricsEvents)
+			metricsRoutes.GET("/vendors", adminMetricsHandler.GetAdminMetricsVendors)
+			metricsRoutes.GET("/marketplace", adminMetricsHandler.GetAdminMetricsMarketplace)
 
 			// Vendor Management
-			// Note: Keeping RequirePermission for granular control if needed, but AdminOnly covers general access.
-			// If we want to strictly follow "Only admin and super_admin", AdminOnly is sufficient.
-			// Existing code used "vendor.verify" permission. I'll keep it for safety but main gate is AdminOnly.
-			adminRoutes.GET("/vendors", adminHandler.GetVendors)
-			adminRoutes.PATCH("/vendors/:id/approve", adminHandler.VerifyVendor)
-			adminRoutes.PATCH("/vendors/:id/reject", adminHandler.RejectVendor)
+			adminRoutes.GET("/vendors", middleware.RequirePermission("vendor.view"), adminHandler.GetVendors)
+			adminRoutes.GET("/vendors/kyc-queue", middleware.RequirePermission("vendor.verify"), adminHandler.GetKYCQueue)
+			adminRoutes.GET("/vendors/:id/kyc", middleware.RequirePermission("vendor.verify"), adminHandler.GetVendorKYC)
+			adminRoutes.PATCH("/vendors/:id/approve", middleware.RequirePermission("vendor.verify"), adminHandler.VerifyVendor)
+			adminRoutes.PATCH("/vendors/:id/reject", middleware.RequirePermission("vendor.verify"), adminHandler.RejectVendor)
+			adminRoutes.PATCH("/vendors/:id/revoke", middleware.RequirePermission("vendor.verify"), adminHandler.RevokeVendorVerification)
+
+			// Review Moderation
+			adminRoutes.GET("/reviews/reported", middleware.RequirePermission("reviews.moderate"), adminHandler.ListReportedReviews)
+			adminRoutes.PATCH("/reviews/:id/hide", middleware.RequirePermission("reviews.moderate"), adminHandler.HideReview)
+			adminRoutes.PATCH("/reviews/:id/restore", middleware.RequirePermission("reviews.moderate"), adminHandler.RestoreReview)
+			adminRoutes.PATCH("/reviews/:id/dismiss-reports", middleware.RequirePermission("reviews.moderate"), adminHandler.DismissReviewReports)
 
 			// User Management
-			adminRoutes.GET("/users", adminHandler.GetUsers)
-
-			// Role Management (Super Admin Only)
-			// We can use a specific route group or just checking the role in handler (which we added middleware for in route)
-			// Better to be explicit in route definition if possible.
-			adminRoutes.PATCH("/users/:id/role", middleware.RequireRole("super_admin"), adminHandler.UpdateUserRole)
+			adminRoutes.GET("/users", middleware.RequirePermission("users.view"), adminHandler.GetUsers)
+			adminRoutes.PATCH("/users/:id/suspend", middleware.RequirePermission("users.manage"), adminHandler.SuspendUser)
+			adminRoutes.PATCH("/users/:id/unsuspend", middleware.RequirePermission("users.manage"), adminHandler.UnsuspendUser)
+			adminRoutes.PATCH("/users/:id/unlock", middleware.RequirePermission("users.manage"), adminHandler.UnlockUser)
+
+			// Role Management (super_admin by default)
+			adminRoutes.PATCH("/users/:id/role", middleware.RequirePermission("users.role"), adminHandler.UpdateUserRole)
+
+			// Permission Management
+			adminRoutes.GET("/permissions", middleware.RequirePermission("permissions.manage"), adminHandler.ListPermissions)
+			adminRoutes.GET("/users/:id/permissions", middleware.RequirePermission("permissions.manage"), adminHandler.GetUserPermissions)
+			adminRoutes.POST("/users/:id/permissions", middleware.RequirePermission("permissions.manage"), adminHandler.GrantPermission)
+			adminRoutes.DELETE("/users/:id/permissions/:code", middleware.RequirePermission("permissions.manage"), adminHandler.RevokePermission)
 		}
 
 		// Super Admin Routes (Legacy/Specific)
 		superAdminRoutes := protected.Group("/superadmin")
-		superAdminRoutes.Use(middleware.RequireRole("super_admin"))
+		superAdminRoutes.Use(middleware.RequireRole("super_admin"), middleware.RequireTwoFactor(cfg))
 		{
 			// Keep existing if needed, or deprecate/move to admin
-			superAdminRoutes.POST("/users/:id/promote-admin", userHandler.PromoteToAdmin)
+			superAdminRoutes.POST("/users/:id/promote-admin", middleware.RequirePermission("users.role"), userHandler.PromoteToAdmin)
+
+			// Impersonation (read-only, audited)
+			superAdminRoutes.POST("/users/:id/impersonate", authHandler.Impersonate)
 		}
 	}
 }
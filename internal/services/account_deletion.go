@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bventy/backend/internal/db"
+)
+
+// StartAccountDeletionWorker purges accounts whose grace period has ended,
+// checking every interval until ctx is cancelled. media is used to delete
+// private files and may be nil when storage is not configured.
+func StartAccountDeletionWorker(ctx context.Context, interval time.Duration, media *MediaService) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if n, err := PurgeDueAccounts(ctx, media); err != nil {
+				log.Printf("ERROR: Account deletion run failed: %v", err)
+			} else if n > 0 {
+				log.Printf("Deleted %d account(s) after their grace period", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// PurgeDueAccounts anonymizes every account scheduled for deletion before now
+func PurgeDueAccounts(ctx context.Context, media *MediaService) (int, error) {
+	rows, err := db.Pool.Query(ctx, `
+		SELECT id FROM users
+		WHERE deletion_scheduled_for IS NOT NULL AND deletion_scheduled_for <= NOW() AND deleted_at IS NULL
+	`)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+
+	deleted := 0
+	for _, id := range ids {
+		if err := AnonymizeUser(ctx, media, id); err != nil {
+			log.Printf("ERROR: Failed to delete account %s: %v", id, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// AnonymizeUser removes a user's personal data. Quotes involving other parties
+// are kept and keep pointing at the (now anonymous) user row, without the text
+// the user wrote in them; the user's own
+// events without quotes, memberships, credentials and media are deleted, and
+// so are vendor verification documents, both the rows and the stored files.
+func AnonymizeUser(ctx context.Context, media *MediaService, userID string) error {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var email string
+	if err := tx.QueryRow(ctx, `SELECT email FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, userID).Scan(&email); err != nil {
+		return err
+	}
+
+	// Verification documents are identity documents: nothing of them is kept.
+	// The files go once the transaction has committed.
+	var kycKeys []string
+	rows, err := tx.Query(ctx, `
+		DELETE FROM vendor_kyc_documents WHERE vendor_id IN (SELECT id FROM vendor_profiles WHERE owner_user_id = $1)
+		RETURNING storage_key
+	`, userID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return err
+		}
+		kycKeys = append(kycKeys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	statements := []string{
+		// Credentials and account security
+		`DELETE FROM user_sessions WHERE user_id = $1`,
+		`DELETE FROM api_keys WHERE user_id = $1`,
+		`DELETE FROM user_identities WHERE user_id = $1`,
+		`DELETE FROM user_permissions WHERE user_id = $1`,
+		`DELETE FROM user_recovery_codes WHERE user_id = $1`,
+		`DELETE FROM password_reset_tokens WHERE user_id = $1`,
+		`DELETE FROM email_verification_tokens WHERE user_id = $1`,
+		`DELETE FROM email_change_requests WHERE user_id = $1`,
+
+		// Reviews stay on the vendor's profile under the tombstone name; reports they filed go
+		`DELETE FROM vendor_review_reports WHERE reporter_user_id = $1`,
+
+		// Quotes stay for the other party, but not what the user wrote in them. On
+		// the vendor side that is everything the deleted profile sent.
+		`UPDATE quote_requests SET message = NULL, special_requirements = NULL, revision_message = NULL, updated_at = NOW()
+		 WHERE organizer_user_id = $1`,
+		`UPDATE quote_requests SET vendor_response = NULL, attachment_url = NULL, updated_at = NOW()
+		 WHERE vendor_id IN (SELECT id FROM vendor_profiles WHERE owner_user_id = $1)`,
+
+		// Events nobody else depends on
+		`DELETE FROM events e WHERE e.organizer_user_id = $1 AND NOT EXISTS (SELECT 1 FROM quote_requests qr WHERE qr.event_id = e.id)`,
+
+		// Groups: hand over to the longest-standing member, or drop if nobody else is in it and nothing depends on it
+		`UPDATE groups g SET owner_user_id = (
+			SELECT gm.user_id FROM group_members gm WHERE gm.group_id = g.id AND gm.user_id != $1
+			ORDER BY (gm.role = 'manager') DESC, gm.created_at LIMIT 1
+		) WHERE g.owner_user_id = $1 AND EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = g.id AND gm.user_id != $1)`,
+		`DELETE FROM groups g WHERE g.owner_user_id = $1 AND NOT EXISTS (
+			SELECT 1 FROM events e JOIN quote_requests qr ON qr.event_id = e.id WHERE e.organizer_group_id = g.id
+		)`,
+		`DELETE FROM group_members WHERE user_id = $1`,
+		`DELETE FROM group_invites WHERE invited_by = $1`,
+
+		// Vendor teams: a member just leaves; an owner's team is disbanded with the profile
+		`DELETE FROM vendor_team_members WHERE user_id = $1 AND role != 'owner'`,
+		`DELETE FROM vendor_team_members WHERE role != 'owner' AND vendor_id IN (SELECT id FROM vendor_profiles WHERE owner_user_id = $1)`,
+		`UPDATE vendor_team_invitations SET revoked_at = NOW()
+		 WHERE accepted_at IS NULL AND revoked_at IS NULL AND vendor_id IN (SELECT id FROM vendor_profiles WHERE owner_user_id = $1)`,
+
+		// Vendor profile: hidden from the directory, contact details and media removed
+		`DELETE FROM vendor_gallery_images WHERE vendor_id IN (SELECT id FROM vendor_profiles WHERE owner_user_id = $1)`,
+		`DELETE FROM vendor_portfolio_files WHERE vendor_id IN (SELECT id FROM vendor_profiles WHERE owner_user_id = $1)`,
+		`DELETE FROM vendor_kyc_reviews WHERE vendor_id IN (SELECT id FROM vendor_profiles WHERE owner_user_id = $1)`,
+		`UPDATE vendor_profiles SET status = 'deleted', whatsapp_link = '', bio = NULL, portfolio_image_url = NULL,
+			gallery_images = '{}', portfolio_files = '[]'::jsonb, kyc_submitted_at = NULL, rejection_reason = NULL, updated_at = NOW()
+		 WHERE owner_user_id = $1`,
+
+		// Activity metadata may contain addresses and IPs
+		`UPDATE platform_activity_log SET metadata = NULL WHERE entity_type = 'user' AND entity_id::text = $1::text`,
+
+		// The user row itself becomes a tombstone
+		`UPDATE users SET
+			email = 'deleted-' || id::text || '@deleted.invalid',
+			password_hash = '', full_name = 'Deleted user', username = NULL, phone = NULL, city = NULL,
+			bio = NULL, profile_image_url = NULL, totp_secret = NULL, totp_enabled_at = NULL,
+			role = 'user', token_version = token_version + 1, deleted_at = NOW(), updated_at = NOW()
+		 WHERE id = $1`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt, userID); err != nil {
+			return err
+		}
+	}
+
+	// Throttle rows are keyed by the normalized address, invites by the raw one
+	if _, err := tx.Exec(ctx, `DELETE FROM login_throttles WHERE scope = 'email' AND key = lower(trim($1))`, email); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM group_invites WHERE lower(invited_email) = lower($1)`, email); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO platform_activity_log (entity_type, entity_id, action_type, actor_user_id) VALUES ('user', $1, 'account_deleted', NULL)`, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	for _, key := range kycKeys {
+		if media == nil {
+			log.Printf("ERROR: Cannot delete KYC file %s: storage is not configured", key)
+			continue
+		}
+		if err := media.DeletePrivateFile(key); err != nil {
+			log.Printf("ERROR: Failed to delete KYC file %s: %v", key, err)
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"mime/multipart"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -25,6 +26,7 @@ type MediaService struct {
 	Client        *s3.Client
 	Bucket        string
 	PublicBaseURL string
+	PrivateBucket string
 }
 
 func NewMediaService(cfg *internalConfig.Config) (*MediaService, error) {
@@ -49,6 +51,7 @@ func NewMediaService(cfg *internalConfig.Config) (*MediaService, error) {
 		Client:        client,
 		Bucket:        cfg.R2Bucket,
 		PublicBaseURL: cfg.R2PublicBaseURL,
+		PrivateBucket: cfg.R2PrivateBucket,
 	}, nil
 }
 
@@ -74,6 +77,48 @@ func (s *MediaService) UploadFile(file multipart.File, originalFilename string,
 	return publicURL, nil
 }
 
+// UploadPrivateFile uploads a file to the private bucket and returns its key.
+// Private files have no public URL; use PresignedURL to hand out a link.
+func (s *MediaService) UploadPrivateFile(file multipart.File, originalFilename string, contentType string, prefixPath string) (string, error) {
+	ext := filepath.Ext(originalFilename)
+	key := strings.TrimPrefix(fmt.Sprintf("%s/%s%s", prefixPath, uuid.New().String(), ext), "/")
+
+	_, err := s.Client.PutObject(context.TODO(), &s3.PutObjectInput{
+		Bucket:      aws.String(s.PrivateBucket),
+		Key:         aws.String(key),
+		Body:        file,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload private file to R2: %w", err)
+	}
+	return key, nil
+}
+
+// PresignedURL returns a download link for a private file that expires after ttl
+func (s *MediaService) PresignedURL(key string, ttl time.Duration) (string, error) {
+	req, err := s3.NewPresignClient(s.Client).PresignGetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(s.PrivateBucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign URL: %w", err)
+	}
+	return req.URL, nil
+}
+
+// DeletePrivateFile deletes a file from the private bucket by its key
+func (s *MediaService) DeletePrivateFile(key string) error {
+	_, err := s.Client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+		Bucket: aws.String(s.PrivateBucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete private file from R2: %w", err)
+	}
+	return nil
+}
+
 // CompressAndUploadImage decodes image, resizes (optional), compresses to WebP, and uploads
 func (s *MediaService) CompressAndUploadImage(file multipart.File, originalFilename string, prefixPath string) (string, error) {
 	// Decode image
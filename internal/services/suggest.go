@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/bventy/backend/internal/db"
+)
+
+// Suggestion is one autocomplete entry
+type Suggestion struct {
+	Type  string `json:"type"` // vendor, category or city
+	Label string `json:"label"`
+	Slug  string `json:"slug,omitempty"`
+	Score int64  `json:"score"`
+}
+
+// SuggestIndex answers prefix queries from memory. It is rebuilt from the
+// database in the background once it is older than its refresh interval, so
+// keystroke traffic never waits on Postgres after the first load.
+type SuggestIndex struct {
+	refreshInterval time.Duration
+	maxCached       int
+
+	mu         sync.RWMutex
+	entries    []Suggestion
+	tokens     []suggestToken // sorted by key
+	cache      map[suggestCacheKey][]Suggestion
+	builtAt    time.Time
+	refreshing bool
+}
+
+// Results depend on the per-type limit as well as the prefix
+type suggestCacheKey struct {
+	prefix  string
+	perType int
+}
+
+type suggestToken struct {
+	key   string
+	entry int
+}
+
+func NewSuggestIndex(refreshInterval time.Duration) *SuggestIndex {
+	return &SuggestIndex{refreshInterval: refreshInterval, maxCached: 5000}
+}
+
+// Suggest returns up to perType suggestions of each type for the prefix
+func (s *SuggestIndex) Suggest(ctx context.Context, prefix string, perType int) []Suggestion {
+	prefix = normalizeSuggestKey(prefix)
+	if prefix == "" {
+		return []Suggestion{}
+	}
+
+	s.ensureFresh(ctx)
+
+	key := suggestCacheKey{prefix: prefix, perType: perType}
+	s.mu.RLock()
+	if cached, ok := s.cache[key]; ok {
+		s.mu.RUnlock()
+		return cached
+	}
+	result := s.lookup(prefix, perType)
+	builtAt := s.builtAt
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	// Skip caching if a refresh swapped the index in the meantime
+	if s.cache != nil && s.builtAt.Equal(builtAt) && len(s.cache) < s.maxCached {
+		s.cache[key] = result
+	}
+	s.mu.Unlock()
+
+	return result
+}
+
+// lookup scans the token range sharing the prefix. Called with s.mu held.
+func (s *SuggestIndex) lookup(prefix string, perType int) []Suggestion {
+	start := sort.Search(len(s.tokens), func(i int) bool { return s.tokens[i].key >= prefix })
+
+	seen := map[int]bool{}
+	var matches []int
+	for i := start; i < len(s.tokens) && strings.HasPrefix(s.tokens[i].key, prefix); i++ {
+		if e := s.tokens[i].entry; !seen[e] {
+			seen[e] = true
+			matches = append(matches, e)
+		}
+	}
+
+	// Labels that start with the prefix beat mid-label word matches, then popularity
+	startsWith := func(e int) bool {
+		return strings.HasPrefix(normalizeSuggestKey(s.entries[e].Label), prefix)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if sa, sb := startsWith(a), startsWith(b); sa != sb {
+			return sa
+		}
+		return s.entries[a].Score > s.entries[b].Score
+	})
+
+	counts := map[string]int{}
+	result := []Suggestion{}
+	for _, e := range matches {
+		entry := s.entries[e]
+		if counts[entry.Type] >= perType {
+			continue
+		}
+		counts[entry.Type]++
+		result = append(result, entry)
+	}
+	return result
+}
+
+// ensureFresh loads the index synchronously the first time and afterwards
+// refreshes it in the background when stale.
+func (s *SuggestIndex) ensureFresh(ctx context.Context) {
+	s.mu.Lock()
+	empty := s.builtAt.IsZero()
+	stale := time.Since(s.builtAt) > s.refreshInterval
+	if !stale || s.refreshing {
+		s.mu.Unlock()
+		return
+	}
+	s.refreshing = true
+	s.mu.Unlock()
+
+	if empty {
+		s.refresh(ctx)
+		return
+	}
+	go s.refresh(context.Background())
+}
+
+func (s *SuggestIndex) refresh(ctx context.Context) {
+	entries, err := loadSuggestions(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshing = false
+	if err != nil {
+		log.Printf("ERROR: Failed to refresh search suggestions: %v", err)
+		// Keep serving what we have and retry shortly rather than on every keystroke
+		s.builtAt = time.Now().Add(30*time.Second - s.refreshInterval)
+		return
+	}
+
+	var tokens []suggestToken
+	for i, e := range entries {
+		seen := map[string]bool{}
+		for _, word := range strings.Fields(normalizeSuggestKey(e.Label)) {
+			if !seen[word] {
+				seen[word] = true
+				tokens = append(tokens, suggestToken{key: word, entry: i})
+			}
+		}
+		// The whole label too, so multi-word prefixes like "royal ca" match
+		if full := normalizeSuggestKey(e.Label); strings.Contains(full, " ") {
+			tokens = append(tokens, suggestToken{key: full, entry: i})
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].key < tokens[j].key })
+
+	s.entries = entries
+	s.tokens = tokens
+	s.cache = make(map[suggestCacheKey][]Suggestion)
+	s.builtAt = time.Now()
+}
+
+// loadSuggestions ranks vendors by profile views (counted from
+// platform_activity_log, see vendor_profiles.view_count) and shortlists;
+// categories and cities by the combined popularity of their vendors.
+func loadSuggestions(ctx context.Context) ([]Suggestion, error) {
+	query := `
+		SELECT 'vendor', business_name, slug, (view_count + 3 * shortlist_count)::bigint
+		FROM vendor_profiles WHERE status = 'verified'
+		UNION ALL
+		SELECT 'category', MIN(category), '', (SUM(view_count + 3 * shortlist_count) + COUNT(*))::bigint
+		FROM vendor_profiles WHERE status = 'verified' GROUP BY lower(category)
+		UNION ALL
+		SELECT 'city', MIN(city), '', (SUM(view_count + 3 * shortlist_count) + COUNT(*))::bigint
+		FROM vendor_profiles WHERE status = 'verified' GROUP BY lower(city)
+	`
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Suggestion
+	for rows.Next() {
+		var e Suggestion
+		if err := rows.Scan(&e.Type, &e.Label, &e.Slug, &e.Score); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// normalizeSuggestKey lower-cases and collapses punctuation to single spaces
+func normalizeSuggestKey(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+	return strings.Join(fields, " ")
+}
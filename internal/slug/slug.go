@@ -0,0 +1,114 @@
+// Package slug builds URL slugs from names and allocates unique ones.
+package slug
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxLength keeps slugs readable; longer names are cut at a word boundary
+const MaxLength = 60
+
+const (
+	maxAttempts    = 8
+	suffixLength   = 4
+	suffixAlphabet = "abcdefghijkmnpqrstuvwxyz23456789" // no 0/o or 1/l lookalikes
+)
+
+// ErrTaken is returned by an insert callback when the candidate slug is
+// already in use, telling Allocate to try another one.
+var ErrTaken = errors.New("slug is taken")
+
+// reserved are path segments and words the frontend uses or that would be
+// confusing as a vendor or group URL.
+var reserved = map[string]bool{
+	"about": true, "admin": true, "api": true, "app": true, "assets": true, "auth": true,
+	"dashboard": true, "edit": true, "events": true, "group": true, "groups": true,
+	"help": true, "login": true, "logout": true, "me": true, "new": true, "null": true,
+	"quotes": true, "search": true, "settings": true, "signup": true, "slug": true,
+	"static": true, "superadmin": true, "support": true, "undefined": true, "vendor": true,
+	"vendors": true, "www": true,
+}
+
+// IsReserved reports whether s may not be used as a slug on its own
+func IsReserved(s string) bool {
+	return reserved[s]
+}
+
+// Make joins the parts with hyphens after transliterating them to lowercase
+// ASCII. Anything that is not a letter or digit becomes a separator. The result
+// is empty when nothing usable is left.
+func Make(parts ...string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(transliterate(part))
+		b.WriteByte(' ')
+	}
+
+	words := strings.FieldsFunc(b.String(), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+
+	s := ""
+	for _, w := range words {
+		next := w
+		if s != "" {
+			next = s + "-" + w
+		}
+		if len(next) > MaxLength {
+			if s == "" {
+				s = w[:MaxLength]
+			}
+			break
+		}
+		s = next
+	}
+	return s
+}
+
+// Allocate finds a free slug for base by calling insert with candidates until
+// one succeeds: base itself, then base with a short random suffix. Reserved or
+// empty bases always get a suffix (an empty base is replaced by fallback).
+// insert must return ErrTaken when the candidate collides with an existing
+// slug; any other error stops the loop.
+func Allocate(base, fallback string, insert func(candidate string) error) (string, error) {
+	if base == "" {
+		base = fallback
+	}
+	if len(base) > MaxLength-suffixLength-1 {
+		base = strings.TrimRight(base[:MaxLength-suffixLength-1], "-")
+	}
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		candidate := base
+		if attempt > 0 || IsReserved(base) {
+			suffix, err := randomSuffix()
+			if err != nil {
+				return "", err
+			}
+			candidate = base + "-" + suffix
+		}
+
+		err := insert(candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, ErrTaken) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("%w: no free slug for %q after %d attempts", ErrTaken, base, maxAttempts)
+}
+
+func randomSuffix() (string, error) {
+	buf := make([]byte, suffixLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, v := range buf {
+		buf[i] = suffixAlphabet[int(v)%len(suffixAlphabet)]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,146 @@
+package slug
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []string
+		want  string
+	}{
+		{"plain", []string{"Raj Caterers"}, "raj-caterers"},
+		{"parts joined", []string{"Raj Caterers", "Mumbai"}, "raj-caterers-mumbai"},
+		{"punctuation collapses", []string{"  Tom & Jerry's -- Events!  "}, "tom-jerry-s-events"},
+		{"digits kept", []string{"Studio 54"}, "studio-54"},
+		{"latin accents", []string{"Café Crème"}, "cafe-creme"},
+		{"latin special letters", []string{"Straße Øresund"}, "strasse-oresund"},
+		{"devanagari", []string{"राज कैटरर्स"}, "raj-kaitarars"},
+		{"devanagari conjunct", []string{"शर्मा"}, "sharma"},
+		{"mixed scripts", []string{"राज", "Pune"}, "raj-pune"},
+		{"unknown script dropped", []string{"東京"}, ""},
+		{"nothing usable", []string{"!!!", " "}, ""},
+		{"no parts", nil, ""},
+		{"cut at word boundary", []string{strings.Repeat("word ", 20)}, strings.TrimSuffix(strings.Repeat("word-", 12), "-")},
+		{"single long word cut", []string{strings.Repeat("a", 70)}, strings.Repeat("a", MaxLength)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.parts...); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.parts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsReserved(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"admin", true},
+		{"vendors", true},
+		{"undefined", true},
+		{"raj-caterers", false},
+		{"admin-raj", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsReserved(tt.s); got != tt.want {
+			t.Errorf("IsReserved(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	errDB := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		base     string
+		fallback string
+		taken    map[string]bool
+		takeAll  bool
+		failWith error
+		want     *regexp.Regexp
+		wantErr  error
+		calls    int
+	}{
+		{
+			name: "free base used as is", base: "raj-caterers", fallback: "vendor",
+			want: regexp.MustCompile(`^raj-caterers$`), calls: 1,
+		},
+		{
+			name: "collision gets a suffix", base: "raj-caterers", fallback: "vendor",
+			taken: map[string]bool{"raj-caterers": true},
+			want:  regexp.MustCompile(`^raj-caterers-[a-km-np-z2-9]{4}$`), calls: 2,
+		},
+		{
+			name: "reserved base always suffixed", base: "admin", fallback: "vendor",
+			want: regexp.MustCompile(`^admin-[a-km-np-z2-9]{4}$`), calls: 1,
+		},
+		{
+			name: "empty base uses fallback", base: "", fallback: "caterer",
+			want: regexp.MustCompile(`^caterer$`), calls: 1,
+		},
+		{
+			name: "reserved fallback suffixed", base: "", fallback: "vendor",
+			want: regexp.MustCompile(`^vendor-[a-km-np-z2-9]{4}$`), calls: 1,
+		},
+		{
+			name: "long base trimmed to leave room for a suffix", base: strings.Repeat("ab-", 20), fallback: "vendor",
+			want: regexp.MustCompile(`^(ab-){18}a$`), calls: 1,
+		},
+		{
+			name: "gives up when every candidate is taken", base: "raj", fallback: "vendor",
+			takeAll: true, wantErr: ErrTaken, calls: maxAttempts,
+		},
+		{
+			name: "other errors stop the loop", base: "raj", fallback: "vendor",
+			failWith: errDB, wantErr: errDB, calls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var candidates []string
+			got, err := Allocate(tt.base, tt.fallback, func(candidate string) error {
+				candidates = append(candidates, candidate)
+				switch {
+				case tt.failWith != nil:
+					return tt.failWith
+				case tt.takeAll || tt.taken[candidate]:
+					return ErrTaken
+				}
+				return nil
+			})
+
+			if len(candidates) != tt.calls {
+				t.Errorf("insert called %d times with %q, want %d", len(candidates), candidates, tt.calls)
+			}
+			for _, c := range candidates {
+				if len(c) > MaxLength {
+					t.Errorf("candidate %q is longer than %d", c, MaxLength)
+				}
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Allocate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Allocate() error = %v", err)
+			}
+			if !tt.want.MatchString(got) {
+				t.Errorf("Allocate() = %q, want match for %s", got, tt.want)
+			}
+			if got != candidates[len(candidates)-1] {
+				t.Errorf("Allocate() = %q, but the last insert was %q", got, candidates[len(candidates)-1])
+			}
+		})
+	}
+}
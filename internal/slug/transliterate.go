@@ -0,0 +1,131 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Devanagari (Hindi, Marathi) is romanized phonetically the way names are
+// usually written in English: no diacritics, long vowels collapsed, and the
+// inherent "a" dropped at the end of a word ("राज" -> "raj", "शर्मा" -> "sharma").
+var devanagariConsonants = map[rune]string{
+	'क': "k", 'ख': "kh", 'ग': "g", 'घ': "gh", 'ङ': "n",
+	'च': "ch", 'छ': "chh", 'ज': "j", 'झ': "jh", 'ञ': "n",
+	'ट': "t", 'ठ': "th", 'ड': "d", 'ढ': "dh", 'ण': "n",
+	'त': "t", 'थ': "th", 'द': "d", 'ध': "dh", 'न': "n",
+	'प': "p", 'फ': "ph", 'ब': "b", 'भ': "bh", 'म': "m",
+	'य': "y", 'र': "r", 'ल': "l", 'ळ': "l", 'व': "v",
+	'श': "sh", 'ष': "sh", 'स': "s", 'ह': "h",
+}
+
+// Consonant + nukta (NFC keeps these decomposed)
+var devanagariNuktaForms = map[rune]string{
+	'क': "q", 'ख': "kh", 'ग': "g", 'ज': "z", 'ड': "r", 'ढ': "rh", 'फ': "f", 'य': "y",
+}
+
+var devanagariVowels = map[rune]string{
+	'अ': "a", 'आ': "a", 'इ': "i", 'ई': "i", 'उ': "u", 'ऊ': "u", 'ऋ': "ri",
+	'ए': "e", 'ऐ': "ai", 'ऑ': "o", 'ओ': "o", 'औ': "au", 'ॐ': "om",
+}
+
+var devanagariVowelSigns = map[rune]string{
+	'ा': "a", 'ि': "i", 'ी': "i", 'ु': "u", 'ू': "u", 'ृ': "ri",
+	'ॅ': "e", 'े': "e", 'ै': "ai", 'ॉ': "o", 'ो': "o", 'ौ': "au",
+}
+
+const (
+	devanagariVirama = '्'
+	devanagariNukta  = '़'
+)
+
+// Latin letters that do not decompose into a base letter plus accents
+var latinSpecial = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'ł': "l", 'þ': "th", 'ı': "i",
+}
+
+// transliterate lowercases s and rewrites it in ASCII as far as it can.
+// Characters from scripts it does not know are dropped.
+func transliterate(s string) string {
+	s = romanizeDevanagari(norm.NFC.String(strings.ToLower(s)))
+
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case r < unicode.MaxASCII:
+			b.WriteRune(r)
+		case unicode.Is(unicode.Mn, r):
+			// accents left over from decomposition (é -> e + ́)
+		case latinSpecial[r] != "":
+			b.WriteString(latinSpecial[r])
+		case unicode.IsSpace(r) || unicode.IsPunct(r):
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+func romanizeDevanagari(s string) string {
+	// ज्ञ is pronounced "gy" (ज्ञान -> gyan), not "jn"
+	runes := []rune(strings.ReplaceAll(s, "ज्ञ", "ग्य"))
+	var b strings.Builder
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if cons, ok := devanagariConsonants[r]; ok {
+			next := i + 1
+			if next < len(runes) && runes[next] == devanagariNukta {
+				if form, ok := devanagariNuktaForms[r]; ok {
+					cons = form
+				}
+				next++
+				i++
+			}
+			b.WriteString(cons)
+			if next >= len(runes) {
+				continue
+			}
+			switch n := runes[next]; {
+			case n == devanagariVirama:
+				i++
+			case devanagariVowelSigns[n] != "":
+				b.WriteString(devanagariVowelSigns[n])
+				i++
+			case isDevanagariLetter(n) || n == 'ं' || n == 'ँ' || n == 'ः':
+				b.WriteByte('a')
+			}
+			// Otherwise the word ends here and the inherent vowel is silent
+			continue
+		}
+
+		switch {
+		case devanagariVowels[r] != "":
+			b.WriteString(devanagariVowels[r])
+		case r == 'ं' || r == 'ँ':
+			// Nasal before a labial is an m (मुंबई -> mumbai)
+			if i+1 < len(runes) && strings.ContainsRune("पफबभम", runes[i+1]) {
+				b.WriteByte('m')
+			} else {
+				b.WriteByte('n')
+			}
+		case r == 'ः':
+			b.WriteByte('h')
+		case r >= '०' && r <= '९':
+			b.WriteRune('0' + (r - '०'))
+		case r == '।' || r == '॥':
+			b.WriteByte(' ')
+		case r == devanagariNukta || r == devanagariVirama || devanagariVowelSigns[r] != "":
+			// stray combining marks
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isDevanagariLetter(r rune) bool {
+	_, consonant := devanagariConsonants[r]
+	_, vowel := devanagariVowels[r]
+	return consonant || vowel
+}